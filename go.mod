@@ -2,4 +2,7 @@ module github.com/nacioboi/go_sfda_map
 
 go 1.23.0
 
-require github.com/nacioboi/go_cpf v0.0.0-20240917043531-6e7b023a22d2
+require (
+	github.com/nacioboi/go_cpf v0.0.0-20240917043531-6e7b023a22d2
+	golang.org/x/sys v0.33.0
+)
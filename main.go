@@ -134,6 +134,29 @@ func format_Number_With_Commas(n int64) string {
 
 func main() {
 	tests.Test_Consistency(16)
+	tests.Test_Lookup(16)
+	tests.Test_Overwrite(16)
+	tests.Test_Deletion(16)
+	tests.Test_Len(16)
+	tests.Test_Zero_Key(16)
+	tests.Test_Hash_Func(16)
+	tests.Test_Builtin_Hash_Funcs(16)
+	tests.Test_Iteration(16)
+	tests.Test_Clear(16)
+	tests.Test_Resizable(1024)
+	tests.Test_Fast_Range_Reduction(1000)
+	tests.Test_Compact(1024)
+	tests.Test_Frozen(1024)
+	tests.Test_Parity_Lanes(1024)
+	tests.Test_Sorted_Buckets(1024)
+	tests.Test_Wide_Buckets(1024)
+	tests.Test_Get_Many(1024)
+	tests.Test_New_From_Slices(1024)
+	tests.Test_Too_Few_Inputs(16)
+	tests.Test_Concurrent(1024)
+	tests.Test_Concurrent_Numeric(1024)
+	tests.Test_RCU(1024)
+	tests.Test_Misuse_Detection(1024)
 
 	debug.SetGCPercent(-1)
 	defer debug.SetGCPercent(100)
@@ -153,6 +176,7 @@ func main() {
 	sfda_64 := sfda_map.New[uint64, uint64](
 		n_normal,
 		sfda_map.With_Performance_Profile[uint64, uint64](sfda_map.PERFORMANCE_PROFILE__8_ENTRIES_PER_BUCKET),
+		sfda_map.With_Experimental_Batched_Gets[uint64, uint64](),
 	)
 	sfda_32 := sfda_map.New[uint64, uint64](
 		n_normal,
@@ -162,6 +186,8 @@ func main() {
 		n_normal,
 		sfda_map.With_Performance_Profile[uint64, uint64](sfda_map.PERFORMANCE_PROFILE__2_ENTRIES_PER_BUCKET),
 	)
+	sfda_resizable := sfda_map.New_SFDA_Resizable_Map[uint64, uint64](1024)
+	sfda_concurrent := sfda_map.New_SFDA_Concurrent_Map[uint64, uint64](n_normal, 256)
 
 	bm_m_f := func() map[uint64]uint64 {
 		return make(map[uint64]uint64)
@@ -194,6 +220,9 @@ func main() {
 	res = tests.Bench_Linear_SFDA_Map_Set(sfda_8, n_normal)
 	fmt.Printf("SFDA  8  :: LINEAR SET            :: %d\n", res.Elapsed_Time)
 	fmt.Printf("SFDA  8  :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
+	res = tests.Bench_Linear_SFDA_Resizable_Map_Set(sfda_resizable, n_normal)
+	fmt.Printf("SFDA RES :: LINEAR SET            :: %d\n", res.Elapsed_Time)
+	fmt.Printf("SFDA RES :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
 
 	// Benchmark Linear Get...
 	res = tests.Bench_Linear_Builtin_Map_Get(bm, n_normal)
@@ -212,6 +241,9 @@ func main() {
 	fmt.Printf("SFDA  8  :: LINEAR GET            :: %d\n", res.Elapsed_Time)
 	fmt.Printf("SFDA  8  :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
 	sfda_1_checksum := res.Checksum
+	res = tests.Bench_Linear_SFDA_Resizable_Map_Get(sfda_resizable, n_normal)
+	fmt.Printf("SFDA RES :: LINEAR GET            :: %d\n", res.Elapsed_Time)
+	fmt.Printf("SFDA RES :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
 
 	// Benchmark Random get...
 	data := tests.Generate_Random_Keys(n_normal)
@@ -221,6 +253,9 @@ func main() {
 	res = tests.Bench_Random_SFDA_Map_Get(sfda_64, data)
 	fmt.Printf("SFDA 64  :: RANDOM GET            :: %d\n", res.Elapsed_Time)
 	fmt.Printf("SFDA 64  :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
+	res = tests.Bench_Random_SFDA_Map_Get_Many(sfda_64, data)
+	fmt.Printf("SFDA 64  :: RANDOM GET MANY       :: %d\n", res.Elapsed_Time)
+	fmt.Printf("SFDA 64  :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
 	res = tests.Bench_Random_SFDA_Map_Get(sfda_32, data)
 	fmt.Printf("SFDA 32  :: RANDOM GET            :: %d\n", res.Elapsed_Time)
 	fmt.Printf("SFDA 32  :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
@@ -230,6 +265,10 @@ func main() {
 	fmt.Printf("SFDA  8  :: RANDOM GET            :: %d\n", res.Elapsed_Time)
 	fmt.Printf("SFDA  8  :: MICROSECONDS PER OP   :: %f\n", float64(res.Elapsed_Time)/float64(n_normal))
 
+	// Benchmark concurrent access...
+	fmt.Println()
+	tests.Bench_Concurrent_Access_SFDA_Map(sfda_concurrent, n_normal)
+
 	// Benchmark memory usage...
 	res = tests.Bench_Mem_Usage_Builtin_Map(bm_m_f, n_memory)
 	fmt.Printf("\nBM       :: MEMORY USAGE           :: %s\n", format_Number_With_Commas(int64(res.Memory_Usage)))
@@ -5,7 +5,17 @@
 
 package main
 
-// func Test_SFDA(t *testing.T) {
-// 	// Call your benchmark function here for trace
-// 	tests.Test_Consistency(1024) // Example: run your consistency test
-// }
+import (
+	"testing"
+
+	tests "github.com/nacioboi/go_sfda_map/tests"
+)
+
+// Mixes `Set` and `Lookup` from several goroutines, run with `go test -race .` to check the concurrent maps.
+func Test_SFDA(t *testing.T) {
+	tests.Test_Consistency(1024)
+	tests.Test_Concurrent(1 << 14)
+	tests.Test_Concurrent_Numeric(1 << 12)
+	tests.Test_RCU(1 << 12)
+	tests.Test_Too_Few_Inputs(16)
+}
@@ -0,0 +1,103 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+const (
+	// How many keys `Get_Many` resolves before it starts probing.
+	// Enough to keep several cache misses in flight, few enough that the batch itself stays in L1.
+	BATCHED_GETS_SIZE = 16
+)
+
+// Look up many keys at once: `out[i]` and `found[i]` receive the value of `keys[i]` and whether it was present.
+// Missing keys get the zero value.
+//
+// With `With_Experimental_Batched_Gets`, the keys are handled in batches of `BATCHED_GETS_SIZE`.
+// Every bucket of a batch is resolved, and its first candidate slot loaded, before any bucket is fully probed.
+// None of those loads depend on each other, so their cache misses overlap instead of being paid one key at a time.
+//
+// Will panic if `out` or `found` is shorter than `keys`.
+//
+// - WARNING: This function is NOT thread-safe.
+func (m *SFDA_Map[KT, VT]) Get_Many(keys []KT, out []VT, found []bool) {
+	if len(out) < len(keys) || len(found) < len(keys) {
+		panic("out and found must be at least as long as keys.")
+	}
+
+	m.guard.begin_read()
+	defer m.guard.end_read()
+
+	if !m.using_batched_gets {
+		for i, key := range keys {
+			out[i], found[i] = m.lookup(key)
+		}
+		return
+	}
+
+	for len(keys) > 0 {
+		n := min(len(keys), BATCHED_GETS_SIZE)
+		m.get_batch(keys[:n], out[:n], found[:n])
+
+		keys = keys[n:]
+		out = out[n:]
+		found = found[n:]
+	}
+}
+
+func (m *SFDA_Map[KT, VT]) get_batch(keys []KT, out []VT, found []bool) {
+	var bucks [BATCHED_GETS_SIZE]bucket[KT]
+	var vals [BATCHED_GETS_SIZE][]VT
+	var slots [BATCHED_GETS_SIZE]int
+
+	// Resolve every bucket, and the slot to try first...
+	for j, key := range keys {
+		index := m.bucket_index(key)
+		bucks[j] = m.buckets[index]
+		vals[j] = m.values[index]
+
+		// Sorted buckets do not use the parity bitmap, their first candidate is slot 0...
+		if !m.sorted {
+			s, ok := m.extras.get(uint64(key))
+			if !ok {
+				s = m.extras.get_sparse(uint64(key))
+			}
+			slots[j] = s
+		}
+	}
+
+	// Pull in the first candidate of every bucket, key and value, which settles most hits.
+	// The value is loaded whether or not the key matches, so that its miss overlaps too...
+	for j, key := range keys {
+		s := slots[j]
+		if s < len(bucks[j].keys) {
+			found[j] = bucks[j].keys[s] == key
+			out[j] = vals[j][s]
+		} else {
+			found[j] = false
+		}
+	}
+
+	// The buckets are in cache by now, probe the rest...
+	for j, key := range keys {
+		if found[j] {
+			continue
+		}
+
+		if i := m.probe_bucket(bucks[j], key); i != -1 {
+			out[j] = vals[j][i]
+			found[j] = true
+			continue
+		}
+
+		if key == 0 && m.has_zero {
+			out[j] = m.zero_value
+			found[j] = true
+			continue
+		}
+
+		var zero VT
+		out[j] = zero
+	}
+}
@@ -0,0 +1,123 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// `New_From_Slices` never sizes a map for fewer inputs than this, so that every profile still gets at least two buckets.
+	// Except for `uint8` keys, which can only ever be sized for 128.
+	BULK_MIN_EXPECTED_NUM_INPUTS = 256
+)
+
+// Build a map from parallel slices of keys and values, in one pass over the buckets rather than one `Set` at a time.
+//
+// The keys of every bucket are counted first, then all buckets are carved out of a single arena, exactly like `Compact` leaves them.
+// The parity bits are written while the buckets are filled.
+//
+// Accepts the same options as `New`, the map is sized for `len(keys)`.
+// Returns an error if the slices differ in length, if a key appears more than once,
+// or if `KT` is too narrow for two buckets of the chosen profile (`uint8` keys with `PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET`).
+// The slices are not retained.
+func New_From_Slices[KT I_Positive_Integer, VT any](
+	keys []KT,
+	values []VT,
+	options ...T_Option[KT, VT],
+) (*SFDA_Map[KT, VT], error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("sfda_map: got %d keys but %d values", len(keys), len(values))
+	}
+
+	expected_num_inputs := clamp_expected_num_inputs[KT](max(uint64(len(keys)), BULK_MIN_EXPECTED_NUM_INPUTS))
+
+	m, err := new_map(expected_num_inputs, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	// Count the keys of every bucket, remembering each key's bucket so that nothing is hashed twice...
+	counts := make([]int, len(m.buckets))
+	indices := make([]KT, len(keys))
+	for i, key := range keys {
+		// Key 0 does not live in a bucket...
+		if key == 0 {
+			continue
+		}
+		indices[i] = m.bucket_index(key)
+		counts[indices[i]]++
+	}
+
+	num_bucketed_entries := 0
+	num_padded_keys := 0
+	for _, count := range counts {
+		num_bucketed_entries += count
+		num_padded_keys += simd_padded_len[KT](count)
+	}
+
+	// Empty windows, each with exactly the capacity its bucket needs, so filling them never reallocates...
+	arena_keys := make([]KT, num_padded_keys)
+	arena_values := make([]VT, num_bucketed_entries)
+	keys_offset := 0
+	values_offset := 0
+	for index, count := range counts {
+		keys_end := keys_offset + simd_padded_len[KT](count)
+		values_end := values_offset + count
+
+		m.buckets[index].keys = arena_keys[keys_offset:keys_offset:keys_end]
+		m.values[index] = arena_values[values_offset:values_offset:values_end]
+
+		keys_offset = keys_end
+		values_offset = values_end
+	}
+
+	for i, key := range keys {
+		if key == 0 {
+			if m.has_zero {
+				return nil, fmt.Errorf("sfda_map: duplicate key %d", key)
+			}
+			m.zero_value = values[i]
+			m.has_zero = true
+			m.num_entries++
+			continue
+		}
+
+		index := indices[i]
+		buck := &m.buckets[index]
+
+		// Sorted buckets are checked once they are sorted, where duplicates end up next to each other...
+		if !m.sorted && m.probe_bucket(*buck, key) != -1 {
+			return nil, fmt.Errorf("sfda_map: duplicate key %d", key)
+		}
+
+		buck.keys = append(buck.keys, key)
+		m.values[index] = append(m.values[index], values[i])
+		m.num_entries++
+
+		if !m.sorted {
+			m.set_parity(key, len(buck.keys)-1)
+		}
+	}
+
+	if m.sorted {
+		for index := range m.buckets {
+			buck := &m.buckets[index]
+			sort.Sort(t_bucket_sorter[KT, VT]{keys: buck.keys, values: m.values[index]})
+
+			for j := 1; j < len(buck.keys); j++ {
+				if buck.keys[j] == buck.keys[j-1] {
+					return nil, fmt.Errorf("sfda_map: duplicate key %d", buck.keys[j])
+				}
+			}
+
+			buck.rebuild_fences()
+		}
+	}
+
+	return m, nil
+}
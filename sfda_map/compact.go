@@ -0,0 +1,51 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+// Take a "snapshot" of the map: collate every bucket's keys and values into one contiguous arena each.
+//
+// Buckets are normally allocated one at a time and end up wherever the allocator put them.
+// After compacting, walking from one bucket to the next walks through memory in order, which is far kinder to the cache.
+//
+// Each bucket's slices become windows into the arena, so the bucket headers themselves are the offsets table and `Find` is unchanged.
+// The windows are only padded up to a whole SIMD block, so the first `Set` that needs more room in a bucket moves that bucket out into its own (overflow) allocation.
+// Calling `Compact` again brings everything back into a single arena.
+//
+// - WARNING: This function is NOT thread-safe.
+func (m *SFDA_Map[KT, VT]) Compact() {
+	m.guard.begin_write()
+	defer m.guard.end_write()
+
+	num_bucketed_entries := 0
+	num_padded_keys := 0
+	for index := range m.buckets {
+		num_bucketed_entries += len(m.buckets[index].keys)
+		num_padded_keys += simd_padded_len[KT](len(m.buckets[index].keys))
+	}
+
+	// A fresh allocation is already zeroed, which is what the SIMD padding needs...
+	arena_keys := make([]KT, num_padded_keys)
+	arena_values := make([]VT, num_bucketed_entries)
+
+	keys_offset := 0
+	values_offset := 0
+	for index := range m.buckets {
+		buck := &m.buckets[index]
+		n := len(buck.keys)
+		keys_end := keys_offset + simd_padded_len[KT](n)
+		values_end := values_offset + n
+
+		copy(arena_keys[keys_offset:], buck.keys)
+		copy(arena_values[values_offset:values_end], m.values[index])
+
+		// Entries keep their slots, so the parity bits stay valid...
+		buck.keys = arena_keys[keys_offset : keys_offset+n : keys_end]
+		m.values[index] = arena_values[values_offset:values_end:values_end]
+
+		keys_offset = keys_end
+		values_offset = values_end
+	}
+}
@@ -0,0 +1,152 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+const (
+	// The dense bitmap never grows past this many words.
+	EXTRAS_MAX_DENSE_WORDS = 1 << 20
+)
+
+// The parity bitmap used by `Find`.
+//
+// For every key it stores the lane of the key's slot, that is `slot % lanes`, so `Find` can start at that lane and stride by `lanes`.
+// A lane takes log2(lanes) bits, rounded up to 1, 2 or 4 so that no key straddles two words.
+//
+// Keys that fall inside `dense` are looked up with a single shift and mask.
+// Any key beyond it has its word kept in `sparse`, so the full range of `KT` is supported without allocating bits for every possible key.
+//
+// The dense region grows on demand by doubling, as long as the keys arriving just past its end suggest the data is still dense.
+type t_extras struct {
+	dense  []uint64
+	sparse map[uint64]uint64
+
+	// log2 of the number of bits per key, and of the number of keys per word...
+	bits_shift     uint8
+	keys_per_shift uint8
+	lane_mask      uint64
+}
+
+func new_extras(num_keys uint64, lanes T_Parity_Lanes) t_extras {
+	var bits_shift uint8
+	switch lanes {
+	case PARITY_LANES__2:
+		bits_shift = 0
+	case PARITY_LANES__4:
+		bits_shift = 1
+	case PARITY_LANES__8:
+		// 3 bits per key, stored in 4...
+		bits_shift = 2
+	default:
+		panic("Invalid number of parity lanes.")
+	}
+
+	keys_per_shift := 6 - bits_shift
+	keys_per_word := uint64(1) << keys_per_shift
+
+	return t_extras{
+		dense:          make([]uint64, (num_keys+keys_per_word-1)/keys_per_word),
+		bits_shift:     bits_shift,
+		keys_per_shift: keys_per_shift,
+		lane_mask:      uint64(lanes.num_lanes()) - 1,
+	}
+}
+
+// The bit offset of `key`'s lane within its word.
+//
+//go:inline
+func (e *t_extras) shift_of(key uint64) uint64 {
+	return (key & (1<<e.keys_per_shift - 1)) << e.bits_shift
+}
+
+// The lane of `key`, and false if it falls outside the dense region, where `get_sparse` has to be asked instead.
+//
+// Callers make that second call themselves: with it, `get` would be too costly for the compiler to inline.
+//
+//go:inline
+func (e *t_extras) get(key uint64) (int, bool) {
+	w := key >> e.keys_per_shift
+	if w < uint64(len(e.dense)) {
+		return int((e.dense[w] >> e.shift_of(key)) & e.lane_mask), true
+	}
+	return 0, false
+}
+
+// Same as `get` for 2 lanes, where the shift and mask are known at compile time.
+//
+//go:inline
+func (e *t_extras) get_two_lanes(key uint64) (int, bool) {
+	w := key / 64
+	if w < uint64(len(e.dense)) {
+		return int(e.dense[w]>>(key%64)) & 1, true
+	}
+	return 0, false
+}
+
+// Kept out of line, it is only reached for keys outside the dense region.
+//
+//go:noinline
+func (e *t_extras) get_sparse(key uint64) int {
+	return int((e.sparse[key>>e.keys_per_shift] >> e.shift_of(key)) & e.lane_mask)
+}
+
+//go:inline
+func (e *t_extras) set(key uint64, lane int) {
+	w := key >> e.keys_per_shift
+	if w >= uint64(len(e.dense)) {
+		e.set_outside_dense(key, lane)
+		return
+	}
+
+	shift := e.shift_of(key)
+	e.dense[w] = e.dense[w]&^(e.lane_mask<<shift) | uint64(lane)<<shift
+}
+
+func (e *t_extras) set_outside_dense(key uint64, lane int) {
+	w := key >> e.keys_per_shift
+
+	// Keys just past the end of the dense region are still dense data, so grow rather than spill...
+	if w < 2*uint64(len(e.dense)) && w < EXTRAS_MAX_DENSE_WORDS {
+		e.grow_dense(w + 1)
+		e.set(key, lane)
+		return
+	}
+
+	shift := e.shift_of(key)
+	word := e.sparse[w]&^(e.lane_mask<<shift) | uint64(lane)<<shift
+
+	// Lane 0 is the default, so drop the word entirely once it is empty...
+	if word == 0 {
+		delete(e.sparse, w)
+		return
+	}
+	if e.sparse == nil {
+		e.sparse = make(map[uint64]uint64)
+	}
+	e.sparse[w] = word
+}
+
+func (e *t_extras) grow_dense(min_words uint64) {
+	new_len := 2 * uint64(len(e.dense))
+	if new_len < min_words {
+		new_len = min_words
+	}
+	if new_len > EXTRAS_MAX_DENSE_WORDS {
+		new_len = EXTRAS_MAX_DENSE_WORDS
+	}
+
+	dense := make([]uint64, new_len)
+	copy(dense, e.dense)
+
+	// Move any sparse words that are now covered by the dense region...
+	for w, word := range e.sparse {
+		if w < new_len {
+			dense[w] = word
+			delete(e.sparse, w)
+		}
+	}
+
+	e.dense = dense
+}
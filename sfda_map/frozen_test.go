@@ -0,0 +1,65 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+// Building must stay linear in the number of keys, placing the last keys of a minimal table used to take minutes from 4M keys on.
+func Test_Frozen_Build_Time(t *testing.T) {
+	const n = 1 << 23
+	r := rand.New(rand.NewSource(1))
+
+	keys := make([]uint64, 0, n)
+	values := make([]uint32, 0, n)
+	seen := make(map[uint64]struct{}, n)
+	for len(keys) < n {
+		k := r.Uint64()
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+		values = append(values, uint32(len(values)))
+	}
+
+	start := time.Now()
+	m, err := Build_Frozen(keys, values)
+	elapsed := time.Since(start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("built %d keys in %v", n, elapsed)
+
+	// Generous enough for a slow or loaded machine, far from what a quadratic build takes...
+	if elapsed > 60*time.Second {
+		t.Fatalf("building %d keys took %v", n, elapsed)
+	}
+
+	if m.Len() != n {
+		t.Fatalf("expected %d entries, got %d", n, m.Len())
+	}
+	for i, k := range keys {
+		if v, ok := m.Lookup(k); !ok || v != uint32(i) {
+			t.Fatalf("key %d: got %d, %v, expected %d", k, v, ok, i)
+		}
+	}
+
+	// The slots past `n` were moved below it, so iterating still sees every key exactly once...
+	count := 0
+	for k, v := range m.All() {
+		if keys[v] != k {
+			t.Fatalf("key %d iterated with the value of key %d", k, keys[v])
+		}
+		count++
+	}
+	if count != n {
+		t.Fatalf("iterated %d entries, expected %d", count, n)
+	}
+}
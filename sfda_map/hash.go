@@ -0,0 +1,137 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/bits"
+)
+
+//
+// Ready-made hash functions for `With_Hash_Func`.
+//
+// The map selects a bucket from the LOW bits of the hash, so every function here makes sure its entropy ends up there.
+// Each constructor picks the variant for the width of `KT` once, so no type switch is left on the hot path.
+//
+
+func _inner__fibonacci__uint64(k uint64) uint64 {
+	return bits.Reverse64(k * 0x9E3779B97F4A7C15)
+}
+
+func _inner__fibonacci__uint32(k uint32) uint64 {
+	return uint64(bits.Reverse32(k * 0x9E3779B9))
+}
+
+func _inner__fibonacci__uint16(k uint16) uint64 {
+	return uint64(bits.Reverse16(k * 0x9E37))
+}
+
+func _inner__fibonacci__uint8(k uint8) uint64 {
+	return uint64(bits.Reverse8(k * 0x9F))
+}
+
+func _inner__murmur3_fmix__uint64(k uint64) uint64 {
+	k ^= k >> 33
+	k *= 0xFF51AFD7ED558CCD
+	k ^= k >> 33
+	k *= 0xC4CEB9FE1A85EC53
+	k ^= k >> 33
+	return k
+}
+
+func _inner__murmur3_fmix__uint32(k uint32) uint64 {
+	k ^= k >> 16
+	k *= 0x85EBCA6B
+	k ^= k >> 13
+	k *= 0xC2B2AE35
+	k ^= k >> 16
+	return uint64(k)
+}
+
+func _inner__seeded__uint64(k uint64, s0 uint64, s1 uint64) uint64 {
+	hi, lo := bits.Mul64(k^s0, s1)
+	return hi ^ lo
+}
+
+// Keys of 32 bits or less fit in a single 64-bit multiply-shift, which is universal for a random odd `s1`.
+func _inner__seeded__uint32(k uint32, s0 uint64, s1 uint64) uint64 {
+	return (uint64(k)*s1 + s0) >> 32
+}
+
+// Returns the key unchanged.
+//
+// This is what the map does without `With_Hash_Func`, so it is only useful as an explicit default.
+func Hash_Identity[KT I_Positive_Integer]() func(KT) uint64 {
+	return func(key KT) uint64 {
+		return uint64(key)
+	}
+}
+
+// Fibonacci (golden ratio) multiplicative hashing.
+//
+// The top bits of the product are where the good bits are, so the product is bit-reversed to bring them down to where the bucket mask looks.
+// Cheap, and spreads strided or clustered keys well.
+func Hash_Fibonacci[KT I_Positive_Integer]() func(KT) uint64 {
+	var zero KT
+	switch any(zero).(type) {
+	case uint64:
+		return func(key KT) uint64 { return _inner__fibonacci__uint64(uint64(key)) }
+	case uint32:
+		return func(key KT) uint64 { return _inner__fibonacci__uint32(uint32(key)) }
+	case uint16:
+		return func(key KT) uint64 { return _inner__fibonacci__uint16(uint16(key)) }
+	case uint8:
+		return func(key KT) uint64 { return _inner__fibonacci__uint8(uint8(key)) }
+	default:
+		panic("Unsupported type.")
+	}
+}
+
+// The MurmurHash3 finalizer (`fmix64`, or `fmix32` for keys of 32 bits or less).
+//
+// Every input bit affects every output bit, at the cost of a few more multiplies than `Hash_Fibonacci`.
+func Hash_Murmur3_Finalizer[KT I_Positive_Integer]() func(KT) uint64 {
+	var zero KT
+	switch any(zero).(type) {
+	case uint64:
+		return func(key KT) uint64 { return _inner__murmur3_fmix__uint64(uint64(key)) }
+	case uint32, uint16, uint8:
+		return func(key KT) uint64 { return _inner__murmur3_fmix__uint32(uint32(key)) }
+	default:
+		panic("Unsupported type.")
+	}
+}
+
+// A keyed hash for keys that may be chosen by an attacker.
+//
+// Without knowing `seed`, nobody can construct a set of keys that all land in the same bucket.
+// Use `New_Random_Hash_Seed` to get a seed.
+func Hash_Seeded[KT I_Positive_Integer](seed uint64) func(KT) uint64 {
+	// Derive two independent keys from the seed, the multiplier must be odd...
+	s0 := _inner__murmur3_fmix__uint64(seed ^ 0x9E3779B97F4A7C15)
+	s1 := _inner__murmur3_fmix__uint64(s0^0xD6E8FEB86659FD93) | 1
+
+	var zero KT
+	switch any(zero).(type) {
+	case uint64:
+		return func(key KT) uint64 { return _inner__seeded__uint64(uint64(key), s0, s1) }
+	case uint32, uint16, uint8:
+		return func(key KT) uint64 { return _inner__seeded__uint32(uint32(key), s0, s1) }
+	default:
+		panic("Unsupported type.")
+	}
+}
+
+// Returns a random seed for `Hash_Seeded`.
+// Will panic if the system's random source fails.
+func New_Random_Hash_Seed() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("Could not read a random seed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
@@ -61,3 +61,12 @@ func next_power_of_two[KT I_Positive_Integer](n KT) KT {
 		panic("Unsupported type.")
 	}
 }
+
+// Cap a number of expected inputs so that `New` can still round it up to a power of two within `KT`.
+func clamp_expected_num_inputs[KT I_Positive_Integer](n uint64) KT {
+	var max_expected_num_inputs uint64 = 1 << 63
+	if max_key := uint64(^KT(0)); max_key < max_expected_num_inputs {
+		max_expected_num_inputs = max_key/2 + 1
+	}
+	return KT(min(n, max_expected_num_inputs))
+}
@@ -0,0 +1,85 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"iter"
+)
+
+// Iterate over every key-value pair, in bucket order.
+// Key 0, if present, comes first.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+// - NOTE: The map must not be modified while iterating, with `sfda_debug` doing so panics.
+func (m *SFDA_Map[KT, VT]) All() iter.Seq2[KT, VT] {
+	return func(yield func(KT, VT) bool) {
+		m.guard.begin_read()
+		defer m.guard.end_read()
+
+		if m.has_zero && !yield(0, m.zero_value) {
+			return
+		}
+
+		for index := range m.buckets {
+			keys := m.buckets[index].keys
+			vals := m.values[index]
+			for i := range keys {
+				if !yield(keys[i], vals[i]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Iterate over every key, in the same order as `All`.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+// - NOTE: The map must not be modified while iterating, with `sfda_debug` doing so panics.
+func (m *SFDA_Map[KT, VT]) Keys() iter.Seq[KT] {
+	return func(yield func(KT) bool) {
+		m.guard.begin_read()
+		defer m.guard.end_read()
+
+		if m.has_zero && !yield(0) {
+			return
+		}
+
+		for index := range m.buckets {
+			for _, key := range m.buckets[index].keys {
+				if !yield(key) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Iterate over every value, in the same order as `All`.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+// - NOTE: The map must not be modified while iterating, with `sfda_debug` doing so panics.
+func (m *SFDA_Map[KT, VT]) Values() iter.Seq[VT] {
+	return func(yield func(VT) bool) {
+		m.guard.begin_read()
+		defer m.guard.end_read()
+
+		if m.has_zero && !yield(m.zero_value) {
+			return
+		}
+
+		for _, vals := range m.values {
+			for _, v := range vals {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
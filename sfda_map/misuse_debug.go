@@ -0,0 +1,65 @@
+//go:build sfda_debug
+
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"sync/atomic"
+)
+
+// Built with `-tags sfda_debug`: every `SFDA_Map` checks that it is not being written to while in use by someone else.
+const MISUSE_DETECTION_ENABLED = true
+
+const (
+	// Set while a write is in progress, the bits below it count the reads in progress...
+	misuse_writer_bit = int64(1) << 62
+)
+
+// Tracks the reads and the write in progress on a `SFDA_Map`, and panics like the builtin map does when they overlap.
+//
+// The state lives behind a pointer so that the map header can still be copied without racing against the counters.
+// A copy shares the state of its original, see `SFDA_RCU_Map` for where that is undone.
+type t_misuse_guard struct {
+	state *atomic.Int64
+}
+
+func new_misuse_guard() t_misuse_guard {
+	return t_misuse_guard{
+		state: new(atomic.Int64),
+	}
+}
+
+//go:inline
+func (g t_misuse_guard) begin_read() {
+	if g.state.Add(1)&misuse_writer_bit != 0 {
+		g.state.Add(-1)
+		panic("sfda_map: concurrent map read and map write")
+	}
+}
+
+//go:inline
+func (g t_misuse_guard) end_read() {
+	g.state.Add(-1)
+}
+
+//go:inline
+func (g t_misuse_guard) begin_write() {
+	if g.state.CompareAndSwap(0, misuse_writer_bit) {
+		return
+	}
+
+	// Nothing was changed, so the other side still gets to notice as well...
+	if g.state.Load()&misuse_writer_bit != 0 {
+		panic("sfda_map: concurrent map writes")
+	}
+	panic("sfda_map: concurrent map read and map write")
+}
+
+//go:inline
+func (g t_misuse_guard) end_write() {
+	g.state.Add(-misuse_writer_bit)
+}
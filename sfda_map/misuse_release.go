@@ -0,0 +1,30 @@
+//go:build !sfda_debug
+
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+// Whether `SFDA_Map` checks for concurrent misuse, build with `-tags sfda_debug` to turn it on.
+const MISUSE_DETECTION_ENABLED = false
+
+// Without `sfda_debug` the guard takes no space and every check compiles away.
+type t_misuse_guard struct{}
+
+func new_misuse_guard() t_misuse_guard {
+	return t_misuse_guard{}
+}
+
+//go:inline
+func (g t_misuse_guard) begin_read() {}
+
+//go:inline
+func (g t_misuse_guard) end_read() {}
+
+//go:inline
+func (g t_misuse_guard) begin_write() {}
+
+//go:inline
+func (g t_misuse_guard) end_write() {}
@@ -11,6 +11,9 @@ const (
 	OPTION_TYPE__WITH_HASH_FUNC T_Option_Type = iota
 	OPTION_TYPE__WITH_PERFORMANCE_PROFILE
 	OPTION_TYPE__WITH_EXPERIMENTAL_BATCHED_GETS
+	OPTION_TYPE__WITH_FAST_RANGE_REDUCTION
+	OPTION_TYPE__WITH_PARITY_LANES
+	OPTION_TYPE__WITH_SORTED_BUCKETS
 )
 
 type T_Option[KT I_Positive_Integer, VT any] struct {
@@ -41,6 +44,10 @@ const (
 	PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET
 )
 
+func (p T_Performance_Profile) num_entries_per_bucket() uint64 {
+	return 2 << p
+}
+
 //
 // The default performance profile is `PERFORMANCE_PROFILE__8_ENTRIES_PER_BUCKET`.
 //
@@ -54,3 +61,77 @@ func With_Performance_Profile[KT I_Positive_Integer, VT any](p T_Performance_Pro
 		other: p,
 	}
 }
+
+//
+// Allow any number of buckets instead of rounding `expected_num_inputs` up to the next power of two.
+//
+// Memory then tracks the real data size, which matters for large maps where rounding up can nearly double it.
+// The price is an extra multiply on every access to pick the bucket, so the default power of two mask stays faster.
+//
+func With_Fast_Range_Reduction[KT I_Positive_Integer, VT any]() T_Option[KT, VT] {
+	return T_Option[KT, VT]{
+		t: OPTION_TYPE__WITH_FAST_RANGE_REDUCTION,
+	}
+}
+
+//
+// Make `Get_Many` resolve a whole batch of buckets before probing any of them, so that the cache misses of different keys overlap.
+//
+// Pays off for random access into maps much larger than the cache.
+// Without this option, `Get_Many` is a plain loop over `Lookup`.
+//
+func With_Experimental_Batched_Gets[KT I_Positive_Integer, VT any]() T_Option[KT, VT] {
+	return T_Option[KT, VT]{
+		t: OPTION_TYPE__WITH_EXPERIMENTAL_BATCHED_GETS,
+		f: func(m *SFDA_Map[KT, VT]) {
+			m.using_batched_gets = true
+		},
+	}
+}
+
+type T_Parity_Lanes uint8
+
+const (
+	PARITY_LANES__2 T_Parity_Lanes = iota
+	PARITY_LANES__4
+	PARITY_LANES__8
+)
+
+func (l T_Parity_Lanes) num_lanes() int {
+	return 2 << l
+}
+
+//
+// The default is `PARITY_LANES__2`: `Find` knows whether a key sits in an even or odd slot, and checks every other entry of the bucket.
+//
+// With 4 or 8 lanes, `Find` knows the key's slot modulo 4 or 8, and only checks every 4th or 8th entry.
+// Each key then takes 2 or 4 bits of the parity bitmap instead of 1.
+//
+// Worth it for the profiles with large buckets, where the probe length dominates.
+//
+// Choosing any number of lanes, even `PARITY_LANES__2`, turns off the SIMD (or SWAR) bucket search,
+// which would otherwise be used instead of the lanes for every bucket of `SIMD_MIN_KEYS` keys or more.
+//
+func With_Parity_Lanes[KT I_Positive_Integer, VT any](l T_Parity_Lanes) T_Option[KT, VT] {
+	return T_Option[KT, VT]{
+		t:     OPTION_TYPE__WITH_PARITY_LANES,
+		other: l,
+	}
+}
+
+//
+// Keep the entries of every bucket sorted by key, with a small fence array that points `Find` close to its target.
+//
+// Inserting and deleting cost more, since entries have to be shifted, but `Find` no longer walks the whole bucket.
+// Only worth it for `PERFORMANCE_PROFILE__64_ENTRIES_PER_BUCKET` and `PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET`.
+//
+// The parity bitmap is not used with sorted buckets, so `With_Parity_Lanes` has no effect.
+//
+func With_Sorted_Buckets[KT I_Positive_Integer, VT any]() T_Option[KT, VT] {
+	return T_Option[KT, VT]{
+		t: OPTION_TYPE__WITH_SORTED_BUCKETS,
+		f: func(m *SFDA_Map[KT, VT]) {
+			m.sorted = true
+		},
+	}
+}
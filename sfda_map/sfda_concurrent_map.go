@@ -0,0 +1,255 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"iter"
+	"math/bits"
+	"sync"
+
+	"golang.org/x/sys/cpu"
+)
+
+const (
+	// No shard is sized for fewer inputs than this, so that every profile still gets at least two buckets per shard.
+	CONCURRENT_MIN_EXPECTED_NUM_INPUTS_PER_SHARD = 256
+)
+
+type t_shard[KT I_Positive_Integer, VT any] struct {
+	mu sync.RWMutex
+	m  *SFDA_Map[KT, VT]
+
+	// Keep every shard's lock on its own cache line, so that goroutines working on different shards do not slow each other down...
+	_ cpu.CacheLinePad
+}
+
+// Splits the keys of a map over a power of two number of parts, each holding its keys in a `SFDA_Map` of its own.
+//
+// A key is split in two: its low `log2(parts)` bits, and the rest, which is what the part stores it under.
+// The part is the low bits plus the high bits of a hash of the rest, modulo the number of parts.
+// For any given rest that is a one-to-one mapping of the low bits onto the parts, so no two keys ever share both part and stored key.
+//
+// The hash spreads keys that only differ in their high bits, such as multiples of the number of parts, over every part.
+// And dividing the keys by the number of parts keeps them as dense within each part as they were in the whole map, which is what the parity bitmap needs.
+type t_key_splitter[KT I_Positive_Integer] struct {
+	// log2(number of parts), and the mask of that many low bits...
+	bits uint8
+	mask uint64
+}
+
+// `num_parts` must be a power of two.
+func new_key_splitter[KT I_Positive_Integer](num_parts int) t_key_splitter[KT] {
+	return t_key_splitter[KT]{
+		bits: uint8(bits.TrailingZeros(uint(num_parts))),
+		mask: uint64(num_parts) - 1,
+	}
+}
+
+// The high bits of the hash of the stored part of a key.
+//
+//go:inline
+func (s t_key_splitter[KT]) offset(stored KT) uint64 {
+	// With a single part the shift is 64, which gives 0...
+	return _inner__murmur3_fmix__uint64(uint64(stored)) >> (64 - s.bits)
+}
+
+// The part of a key, and the key it is stored under within that part.
+//
+//go:inline
+func (s t_key_splitter[KT]) split(key KT) (int, KT) {
+	stored := key >> s.bits
+	return int((uint64(key) + s.offset(stored)) & s.mask), stored
+}
+
+// The inverse of `split`.
+//
+//go:inline
+func (s t_key_splitter[KT]) join(part int, stored KT) KT {
+	low := (uint64(part) - s.offset(stored)) & s.mask
+	return stored<<s.bits | KT(low)
+}
+
+// A `SFDA_Map` that can be shared between goroutines.
+//
+// The keys are split across independent `SFDA_Map` shards, each behind its own read-write lock.
+// Goroutines only contend when they touch the same shard.
+// See `t_key_splitter` for how a key's shard is picked.
+type SFDA_Concurrent_Map[KT I_Positive_Integer, VT any] struct {
+	shards   []t_shard[KT, VT]
+	splitter t_key_splitter[KT]
+}
+
+// `num_shards` must be a power of two.
+// A few times the number of goroutines sharing the map is a good start.
+//
+// Accepts the same options as `New`, they are applied to every shard.
+// Note that a shard only sees keys divided by `num_shards`, so that is also what a `With_Hash_Func` hash function receives.
+func New_SFDA_Concurrent_Map[KT I_Positive_Integer, VT any](
+	expected_num_inputs KT,
+	num_shards int,
+	options ...T_Option[KT, VT],
+) *SFDA_Concurrent_Map[KT, VT] {
+	if num_shards <= 0 || num_shards&(num_shards-1) != 0 {
+		panic("num_shards must be a power of two.")
+	}
+
+	per_shard := max(uint64(expected_num_inputs)/uint64(num_shards), CONCURRENT_MIN_EXPECTED_NUM_INPUTS_PER_SHARD)
+
+	m := &SFDA_Concurrent_Map[KT, VT]{
+		shards:   make([]t_shard[KT, VT], num_shards),
+		splitter: new_key_splitter[KT](num_shards),
+	}
+	for i := range m.shards {
+		m.shards[i].m = New(clamp_expected_num_inputs[KT](per_shard), options...)
+	}
+	return m
+}
+
+// The shard of a key, and the key it is stored under within that shard.
+//
+//go:inline
+func (m *SFDA_Concurrent_Map[KT, VT]) split(key KT) (*t_shard[KT, VT], KT) {
+	i, stored := m.splitter.split(key)
+	return &m.shards[i], stored
+}
+
+// Set a key-value pair in the map.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Set(key KT, value VT) {
+	s, stored := m.split(key)
+	s.mu.Lock()
+	s.m.Set(stored, value)
+	s.mu.Unlock()
+}
+
+// Same as `Set` but also returns the previous value and whether the key already existed.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Set_Returning_Old(key KT, value VT) (old VT, existed bool) {
+	s, stored := m.split(key)
+	s.mu.Lock()
+	old, existed = s.m.Set_Returning_Old(stored, value)
+	s.mu.Unlock()
+	return old, existed
+}
+
+// Get the value of a key and whether it was present.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Lookup(key KT) (VT, bool) {
+	s, stored := m.split(key)
+	s.mu.RLock()
+	v, ok := s.m.Lookup(stored)
+	s.mu.RUnlock()
+	return v, ok
+}
+
+// Get the value of a key, or `def` if it is not present.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Get_Or_Default(key KT, def VT) VT {
+	if v, ok := m.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// Delete an entry from the map and return a boolean indicating whether the entry was found.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Delete(key KT) bool {
+	s, stored := m.split(key)
+	s.mu.Lock()
+	found := s.m.Delete(stored)
+	s.mu.Unlock()
+	return found
+}
+
+// The number of entries stored in the map.
+// The shards are counted one after the other, so with concurrent writers the result is only a close estimate.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		n += s.m.Len()
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// Iterate over every key-value pair, shard by shard.
+//
+// Each shard is copied under its read lock and then yielded without holding any lock, so the loop body may use the map.
+// Entries set or deleted while iterating may or may not be seen.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) All() iter.Seq2[KT, VT] {
+	return m.all(false)
+}
+
+// `exclusive` copies each shard under its write lock rather than its read lock.
+func (m *SFDA_Concurrent_Map[KT, VT]) all(exclusive bool) iter.Seq2[KT, VT] {
+	return func(yield func(KT, VT) bool) {
+		var keys []KT
+		var values []VT
+		for i := range m.shards {
+			s := &m.shards[i]
+
+			keys = keys[:0]
+			values = values[:0]
+			if exclusive {
+				s.mu.Lock()
+			} else {
+				s.mu.RLock()
+			}
+			for stored, v := range s.m.All() {
+				keys = append(keys, m.splitter.join(i, stored))
+				values = append(values, v)
+			}
+			if exclusive {
+				s.mu.Unlock()
+			} else {
+				s.mu.RUnlock()
+			}
+
+			for j := range keys {
+				if !yield(keys[j], values[j]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Iterate over every key, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Keys() iter.Seq[KT] {
+	return func(yield func(KT) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over every value, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Map[KT, VT]) Values() iter.Seq[VT] {
+	return func(yield func(VT) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
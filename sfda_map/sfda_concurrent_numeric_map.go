@@ -0,0 +1,186 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"iter"
+	"sync"
+)
+
+type I_Integer interface {
+	int | int8 | int16 | int32 | int64 | uint | uint8 | uint16 | uint32 | uint64 | uintptr
+}
+
+// A `SFDA_Concurrent_Map` of integers, with read-modify-write operations such as counters need.
+//
+// Besides its shard's lock, every bucket has a lock of its own.
+// Changing the value of a key that is already present only holds its shard's lock for reading, and its bucket's lock:
+// so it waits for nothing but writes to the same bucket, and structural changes (new keys, `Delete`, ...) to the same shard.
+// Only adding a key takes the whole shard.
+//
+// Reads hold the bucket lock for reading, so every method of `SFDA_Concurrent_Map` that sees values is replaced here.
+type SFDA_Concurrent_Numeric_Map[KT I_Positive_Integer, VT I_Integer] struct {
+	*SFDA_Concurrent_Map[KT, VT]
+
+	// One lock per bucket of every shard...
+	bucket_locks [][]sync.RWMutex
+}
+
+// Same arguments as `New_SFDA_Concurrent_Map`.
+func New_SFDA_Concurrent_Numeric_Map[KT I_Positive_Integer, VT I_Integer](
+	expected_num_inputs KT,
+	num_shards int,
+	options ...T_Option[KT, VT],
+) *SFDA_Concurrent_Numeric_Map[KT, VT] {
+	m := &SFDA_Concurrent_Numeric_Map[KT, VT]{
+		SFDA_Concurrent_Map: New_SFDA_Concurrent_Map(expected_num_inputs, num_shards, options...),
+		bucket_locks:        make([][]sync.RWMutex, num_shards),
+	}
+	for i := range m.shards {
+		m.bucket_locks[i] = make([]sync.RWMutex, len(m.shards[i].m.buckets))
+	}
+	return m
+}
+
+// The shard of a key, the lock of its bucket, and the key it is stored under within that shard.
+//
+//go:inline
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) split_with_lock(key KT) (*t_shard[KT, VT], *sync.RWMutex, KT) {
+	i, stored := m.splitter.split(key)
+	s := &m.shards[i]
+	return s, &m.bucket_locks[i][s.m.bucket_index(stored)], stored
+}
+
+// Apply `f` to the value of a key that is already present, with only its bucket locked for writing.
+// Returns false, without calling `f`, if the key is not present.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) update_in_place(key KT, f func(p *VT)) bool {
+	s, l, stored := m.split_with_lock(key)
+	s.mu.RLock()
+	l.Lock()
+
+	// Only a value changes, which the guard need not hear about: the bucket lock keeps readers of it out...
+	s.m.guard.begin_read()
+	p := s.m.value_ptr(stored)
+	if p != nil {
+		f(p)
+	}
+	s.m.guard.end_read()
+
+	l.Unlock()
+	s.mu.RUnlock()
+	return p != nil
+}
+
+// Add `delta` to the value of a key and return the new value.
+// A key that is not present counts as 0, so it ends up holding `delta`.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Add(key KT, delta VT) VT {
+	var v VT
+	if m.update_in_place(key, func(p *VT) { *p += delta; v = *p }) {
+		return v
+	}
+
+	// Another goroutine may have added the key in the meantime...
+	s, stored := m.split(key)
+	s.mu.Lock()
+	s.m.guard.begin_write()
+
+	v = delta
+	if p := s.m.value_ptr(stored); p != nil {
+		*p += delta
+		v = *p
+	} else {
+		s.m.set(stored, delta)
+	}
+
+	s.m.guard.end_write()
+	s.mu.Unlock()
+	return v
+}
+
+// Set the value of a key to `new` if it currently holds `old`, and return whether it did.
+// Like `sync.Map`, a key that is not present never matches.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Compare_And_Swap(key KT, old VT, new VT) bool {
+	swapped := false
+	m.update_in_place(key, func(p *VT) {
+		if *p == old {
+			*p = new
+			swapped = true
+		}
+	})
+	return swapped
+}
+
+// Set the value of a key, and return the previous value and whether the key was present.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Swap(key KT, new VT) (old VT, loaded bool) {
+	if m.update_in_place(key, func(p *VT) { old, *p = *p, new }) {
+		return old, true
+	}
+	return m.Set_Returning_Old(key, new)
+}
+
+// Get the value of a key and whether it was present.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Lookup(key KT) (VT, bool) {
+	s, l, stored := m.split_with_lock(key)
+	s.mu.RLock()
+	l.RLock()
+	v, ok := s.m.Lookup(stored)
+	l.RUnlock()
+	s.mu.RUnlock()
+	return v, ok
+}
+
+// Get the value of a key, or `def` if it is not present.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Get_Or_Default(key KT, def VT) VT {
+	if v, ok := m.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// Iterate over every key-value pair, shard by shard, see `SFDA_Concurrent_Map.All`.
+//
+// Each shard is copied under its write lock, since values may be changed under its read lock.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) All() iter.Seq2[KT, VT] {
+	return m.all(true)
+}
+
+// Iterate over every key, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Keys() iter.Seq[KT] {
+	return func(yield func(KT) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over every value, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe.
+func (m *SFDA_Concurrent_Numeric_Map[KT, VT]) Values() iter.Seq[VT] {
+	return func(yield func(VT) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,292 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"fmt"
+	"iter"
+	"math/bits"
+	"slices"
+)
+
+const (
+	// The average number of keys that share a displacement.
+	// Larger groups make the map smaller but slower to build.
+	FROZEN_AVERAGE_GROUP_SIZE = 4
+
+	// Give up on a seed once a single group needs more displacements than this, and start over with another one.
+	FROZEN_MAX_DISPLACEMENT = 1 << 20
+
+	// Keys are placed into a table this much larger than their number.
+	// Without any spare slot, the last groups would need about `n` tries each to find the last free slots.
+	FROZEN_SPARE_SLOTS_PERCENT = 1
+
+	FROZEN_INITIAL_SEED = 0x5FDA5FDA5FDA5FDA
+)
+
+// A read-only map built around a minimal perfect hash.
+//
+// Every key has exactly one slot, and the `n` keys fill exactly `n` slots.
+// A lookup is therefore a couple of multiplies, one displacement load and a single probe, with no parity bitmap and no bucket walk.
+//
+// The hash follows the "hash and displace" scheme of CHD:
+// keys are split into small groups, and each group is given the displacement that lands all of its keys in free slots.
+//
+// Like CHD, the keys are first placed into a table with `FROZEN_SPARE_SLOTS_PERCENT` percent of spare slots, which keeps building linear in `n`.
+// The few keys that land past slot `n` are then moved into the slots left free below it, through a small remapping table.
+type SFDA_Frozen_Map[KT I_Positive_Integer, VT any] struct {
+	seed          uint64
+	displacements []uint32
+	num_groups    uint64
+
+	// The size of the table the keys were placed into, and where each slot past `n` was moved to...
+	table_size uint64
+	remap      []uint32
+
+	keys   []KT
+	values []VT
+}
+
+//go:inline
+func _inner__frozen_hash(key uint64, seed uint64) uint64 {
+	return _inner__murmur3_fmix__uint64(key ^ seed)
+}
+
+//go:inline
+func _inner__frozen_slot(h uint64, displacement uint32, n uint64) uint64 {
+	slot, _ := bits.Mul64(_inner__murmur3_fmix__uint64(h^(uint64(displacement)*0x9E3779B97F4A7C15)), n)
+	return slot
+}
+
+// Build a frozen map from parallel slices of keys and values.
+//
+// Returns an error if the slices differ in length or if a key appears more than once.
+// The slices are not retained.
+func Build_Frozen[KT I_Positive_Integer, VT any](keys []KT, values []VT) (*SFDA_Frozen_Map[KT, VT], error) {
+	if len(keys) != len(values) {
+		return nil, fmt.Errorf("sfda_map: got %d keys but %d values", len(keys), len(values))
+	}
+
+	n := uint64(len(keys))
+	num_groups := (n + FROZEN_AVERAGE_GROUP_SIZE - 1) / FROZEN_AVERAGE_GROUP_SIZE
+	table_size := n + n*FROZEN_SPARE_SLOTS_PERCENT/100 + 1
+
+	m := &SFDA_Frozen_Map[KT, VT]{
+		displacements: make([]uint32, num_groups),
+		num_groups:    num_groups,
+		table_size:    table_size,
+		remap:         make([]uint32, table_size-n),
+		keys:          make([]KT, n),
+		values:        make([]VT, n),
+	}
+	if n == 0 {
+		return m, nil
+	}
+
+	hashes := make([]uint64, n)
+	group_of := make([]uint64, n)
+	offsets := make([]uint64, num_groups+1)
+	members := make([]uint32, n)
+	order := make([]uint64, num_groups)
+	taken := make([]bool, table_size)
+	slot_of := make([]uint64, n)
+	slots := make([]uint64, 0, 16)
+
+	for seed := uint64(FROZEN_INITIAL_SEED); ; seed = _inner__murmur3_fmix__uint64(seed + 1) {
+		// Split the keys into groups, counting sort style...
+		clear(offsets)
+		for i, key := range keys {
+			hashes[i] = _inner__frozen_hash(uint64(key), seed)
+			group_of[i], _ = bits.Mul64(hashes[i], num_groups)
+			offsets[group_of[i]+1]++
+		}
+		for g := uint64(0); g < num_groups; g++ {
+			offsets[g+1] += offsets[g]
+		}
+		fill := slices.Clone(offsets[:num_groups])
+		for i := range keys {
+			members[fill[group_of[i]]] = uint32(i)
+			fill[group_of[i]]++
+		}
+
+		// The hash is a bijection, so equal hashes can only come from equal keys...
+		for g := uint64(0); g < num_groups; g++ {
+			group := members[offsets[g]:offsets[g+1]]
+			for a := 0; a < len(group); a++ {
+				for b := a + 1; b < len(group); b++ {
+					if hashes[group[a]] == hashes[group[b]] {
+						return nil, fmt.Errorf("sfda_map: duplicate key %d", keys[group[a]])
+					}
+				}
+			}
+		}
+
+		// Place the largest groups first, while the table is still mostly empty...
+		for g := range order {
+			order[g] = uint64(g)
+		}
+		slices.SortFunc(order, func(a, b uint64) int {
+			return int(offsets[b+1]-offsets[b]) - int(offsets[a+1]-offsets[a])
+		})
+
+		clear(taken)
+		ok := true
+		for _, g := range order {
+			group := members[offsets[g]:offsets[g+1]]
+			if len(group) == 0 {
+				break
+			}
+
+			displacement := uint32(0)
+			for ; displacement < FROZEN_MAX_DISPLACEMENT; displacement++ {
+				slots = slots[:0]
+				for _, i := range group {
+					slot := _inner__frozen_slot(hashes[i], displacement, table_size)
+					if taken[slot] || slices.Contains(slots, slot) {
+						break
+					}
+					slots = append(slots, slot)
+				}
+				if len(slots) == len(group) {
+					break
+				}
+			}
+			if displacement == FROZEN_MAX_DISPLACEMENT {
+				ok = false
+				break
+			}
+
+			m.displacements[g] = displacement
+			for j, i := range group {
+				taken[slots[j]] = true
+				slot_of[i] = slots[j]
+			}
+		}
+
+		if ok {
+			m.seed = seed
+			m.fill(keys, values, taken, slot_of)
+			return m, nil
+		}
+
+		// Unlucky seed, start over...
+		clear(m.displacements)
+	}
+}
+
+// Move the keys placed past slot `n` into the free slots below it, and lay out the keys and values.
+func (m *SFDA_Frozen_Map[KT, VT]) fill(keys []KT, values []VT, taken []bool, slot_of []uint64) {
+	n := uint64(len(keys))
+
+	// There are exactly as many free slots below `n` as there are taken slots past it...
+	free := uint64(0)
+	for spare := n; spare < m.table_size; spare++ {
+		if !taken[spare] {
+			continue
+		}
+		for taken[free] {
+			free++
+		}
+		m.remap[spare-n] = uint32(free)
+		free++
+	}
+
+	for i, slot := range slot_of {
+		if slot >= n {
+			slot = uint64(m.remap[slot-n])
+		}
+		m.keys[slot] = keys[i]
+		m.values[slot] = values[i]
+	}
+}
+
+// Build a read-only copy of the map.
+// The map itself is left untouched and can keep being used.
+//
+// - WARNING: This function is NOT thread-safe.
+func (m *SFDA_Map[KT, VT]) Freeze() *SFDA_Frozen_Map[KT, VT] {
+	keys := make([]KT, 0, m.num_entries)
+	values := make([]VT, 0, m.num_entries)
+	for k, v := range m.All() {
+		keys = append(keys, k)
+		values = append(values, v)
+	}
+
+	frozen, err := Build_Frozen(keys, values)
+	if err != nil {
+		// The keys of a map are always unique...
+		panic(err)
+	}
+	return frozen
+}
+
+// Get the value of a key and whether it was present.
+//
+// - NOTE: This function is thread-safe, the map never changes.
+//
+//go:inline
+func (m *SFDA_Frozen_Map[KT, VT]) Lookup(key KT) (VT, bool) {
+	n := uint64(len(m.keys))
+	if n == 0 {
+		var zero VT
+		return zero, false
+	}
+
+	h := _inner__frozen_hash(uint64(key), m.seed)
+	g, _ := bits.Mul64(h, m.num_groups)
+	slot := _inner__frozen_slot(h, m.displacements[g], m.table_size)
+	if slot >= n {
+		slot = uint64(m.remap[slot-n])
+	}
+
+	if m.keys[slot] != key {
+		var zero VT
+		return zero, false
+	}
+	return m.values[slot], true
+}
+
+// Get the value of a key, or `def` if it is not present.
+//
+// - NOTE: This function is thread-safe, the map never changes.
+func (m *SFDA_Frozen_Map[KT, VT]) Get_Or_Default(key KT, def VT) VT {
+	if v, ok := m.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// The number of entries stored in the map.
+func (m *SFDA_Frozen_Map[KT, VT]) Len() int {
+	return len(m.keys)
+}
+
+// Iterate over every key-value pair, in slot order.
+//
+// - NOTE: This function is thread-safe, the map never changes.
+func (m *SFDA_Frozen_Map[KT, VT]) All() iter.Seq2[KT, VT] {
+	return func(yield func(KT, VT) bool) {
+		for i := range m.keys {
+			if !yield(m.keys[i], m.values[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over every key, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe, the map never changes.
+func (m *SFDA_Frozen_Map[KT, VT]) Keys() iter.Seq[KT] {
+	return slices.Values(m.keys)
+}
+
+// Iterate over every value, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe, the map never changes.
+func (m *SFDA_Frozen_Map[KT, VT]) Values() iter.Seq[VT] {
+	return slices.Values(m.values)
+}
@@ -5,70 +5,125 @@
 
 package sfda_map
 
+import (
+	"fmt"
+	"math/bits"
+)
+
 type I_Positive_Integer interface {
 	uint8 | uint16 | uint32 | uint64
 }
 
 type bucket[KT I_Positive_Integer] struct {
 	keys []KT
+
+	// Only used with `With_Sorted_Buckets`, see `sorted.go`...
+	fences []KT
 }
 
 // Super-Fast Direct-Access Map.
+//
+// Not safe for concurrent use: build with `-tags sfda_debug` to have a write that overlaps another write or a read panic, instead of silently corrupting the map.
 type SFDA_Map[KT I_Positive_Integer, VT any] struct {
-	extras []int
+	// Empty unless built with `sfda_debug`, and kept first since a trailing zero-size field would still be padded...
+	guard t_misuse_guard
+
+	extras t_extras
 
 	values                 [][]VT
 	buckets                []bucket[KT]
 	num_buckets_m1         KT
 	num_entries_per_bucket uint64
+	num_entries            int
+	num_lanes              int
+
+	// Only used with `With_Fast_Range_Reduction`, where `num_buckets_m1` is not a mask...
+	num_buckets      uint64
+	using_fast_range bool
 
 	users_chosen_hash_func func(KT) uint64
 	using_users_hash_func  bool
 
+	sorted bool
+
+	using_batched_gets bool
+
+	// The SIMD kernel picked for `KT` at construction, or `nil` if there is none for this CPU or `With_Parity_Lanes` was used...
+	find_kernel func([]KT, KT) int
+
+	// Set by `New` when no option changes how a key is found: masked bucket index, 2 parity lanes, unsorted buckets.
+	// `probe` checks it first, so the default map pays for none of the options...
+	plain bool
+
+	// Key 0 lives out-of-band so that it never occupies a bucket slot...
+	zero_value VT
+	has_zero   bool
+
 	profile T_Performance_Profile
 }
 
+// Will panic if `expected_num_inputs` is too small for two buckets of the chosen profile, unless with `With_Fast_Range_Reduction`.
 func New[KT I_Positive_Integer, VT any](
 	expected_num_inputs KT,
 	options ...T_Option[KT, VT],
 ) *SFDA_Map[KT, VT] {
-	expected_num_inputs = next_power_of_two(expected_num_inputs)
+	m, err := new_map(expected_num_inputs, options...)
+	if err != nil {
+		panic(err.Error())
+	}
+	return m
+}
 
+// Same as `New`, but returns an error rather than panic when the inputs are too few for two buckets.
+func new_map[KT I_Positive_Integer, VT any](
+	expected_num_inputs KT,
+	options ...T_Option[KT, VT],
+) (*SFDA_Map[KT, VT], error) {
 	profile := PERFORMANCE_PROFILE__8_ENTRIES_PER_BUCKET
+	using_fast_range := false
+	lanes := PARITY_LANES__2
+	lanes_chosen := false
 	for _, opt := range options {
-		if opt.t == OPTION_TYPE__WITH_PERFORMANCE_PROFILE {
+		switch opt.t {
+		case OPTION_TYPE__WITH_PERFORMANCE_PROFILE:
 			profile = opt.other.(T_Performance_Profile)
+		case OPTION_TYPE__WITH_FAST_RANGE_REDUCTION:
+			using_fast_range = true
+		case OPTION_TYPE__WITH_PARITY_LANES:
+			lanes = opt.other.(T_Parity_Lanes)
+			lanes_chosen = true
 		}
 	}
 
-	var num_buckets KT
-	switch profile {
-	case PERFORMANCE_PROFILE__2_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 2
-	case PERFORMANCE_PROFILE__4_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 4
-	case PERFORMANCE_PROFILE__8_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 8
-	case PERFORMANCE_PROFILE__16_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 16
-	case PERFORMANCE_PROFILE__32_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 32
-	case PERFORMANCE_PROFILE__64_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 64
-	case PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET:
-		num_buckets = expected_num_inputs / 128
-	default:
+	if profile > PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET {
 		panic("Invalid performance profile.")
 	}
+	num_entries_per_bucket := KT(profile.num_entries_per_bucket())
 
-	if num_buckets%2 != 0 {
-		panic("numBuckets should be a multiple of 2.")
+	var num_buckets KT
+	if using_fast_range {
+		// Any number of buckets will do, so allocate only what the data needs...
+		num_buckets = expected_num_inputs / num_entries_per_bucket
+		if expected_num_inputs%num_entries_per_bucket != 0 || num_buckets == 0 {
+			num_buckets++
+		}
+	} else {
+		requested := expected_num_inputs
+		expected_num_inputs = next_power_of_two(expected_num_inputs)
+		num_buckets = expected_num_inputs / num_entries_per_bucket
+
+		// A power of two divided by another is 0, 1 or even...
+		if num_buckets < 2 {
+			return nil, fmt.Errorf(
+				"sfda_map: %d expected inputs are too few for two buckets of %d entries, expect more or use `With_Fast_Range_Reduction`",
+				requested, num_entries_per_bucket,
+			)
+		}
 	}
 
 	// Allocate buckets...
-	num_buckets_runtime := any(num_buckets).(uint64)
+	num_buckets_runtime := uint64(num_buckets)
 	buckets := make([]bucket[KT], num_buckets_runtime)
-	estimated_num_entries_per_bucket := expected_num_inputs / num_buckets
 	for i := uint64(0); i < num_buckets_runtime; i++ {
 		b := bucket[KT]{
 			keys: make([]KT, 0),
@@ -77,91 +132,349 @@ func New[KT I_Positive_Integer, VT any](
 		buckets[i] = b
 	}
 
+	// Parity lanes that were asked for take priority over the bucket search kernels...
+	var find_kernel func([]KT, KT) int
+	if !lanes_chosen {
+		find_kernel = find_kernel_for[KT]()
+	}
+
 	// Instantiate...
 	inst := SFDA_Map[KT, VT]{
-		extras:                 make([]int, int(expected_num_inputs+1)),
+		guard:                  new_misuse_guard(),
+		extras:                 new_extras(uint64(expected_num_inputs)+1, lanes),
 		values:                 make([][]VT, num_buckets),
 		buckets:                buckets,
 		num_buckets_m1:         num_buckets - 1,
-		num_entries_per_bucket: uint64(estimated_num_entries_per_bucket),
+		num_entries_per_bucket: uint64(num_entries_per_bucket),
+		num_lanes:              lanes.num_lanes(),
+		find_kernel:            find_kernel,
+		num_buckets:            num_buckets_runtime,
+		using_fast_range:       using_fast_range,
 		profile:                profile,
 	}
 
 	// Apply options...
 	for _, opt := range options {
-		if opt.t != OPTION_TYPE__WITH_PERFORMANCE_PROFILE {
+		switch opt.t {
+		case OPTION_TYPE__WITH_PERFORMANCE_PROFILE, OPTION_TYPE__WITH_FAST_RANGE_REDUCTION, OPTION_TYPE__WITH_PARITY_LANES:
+			// Already taken into account above...
+		default:
 			opt.f(&inst)
 		}
 	}
 
-	return &inst
+	inst.plain = !inst.using_fast_range && !inst.using_users_hash_func && !inst.sorted && inst.num_lanes == 2
+
+	return &inst, nil
 }
 
 func (m *SFDA_Map[KT, VT]) Enquire_Number_Of_Buckets() KT {
 	return m.num_buckets_m1 + 1
 }
 
+// The number of entries currently stored in the map.
+//
+// - WARNING: This function is NOT thread-safe.
+func (m *SFDA_Map[KT, VT]) Len() int {
+	return m.num_entries
+}
+
+// The average number of entries per bucket, relative to what the chosen `T_Performance_Profile` aims for.
+//
+// 1.0 means the map is exactly as full as its profile intends.
+// Anything well above that means the map has outgrown the `expected_num_inputs` it was created with, and lookups are getting slower.
+//
+// - WARNING: This function is NOT thread-safe.
+func (m *SFDA_Map[KT, VT]) Enquire_Load_Factor() float64 {
+	num_buckets := float64(m.num_buckets_m1) + 1
+	return float64(m.num_entries) / num_buckets / float64(m.num_entries_per_bucket)
+}
+
+// Select the bucket for a key.
+//
+// Without `With_Hash_Func` or `With_Fast_Range_Reduction` this is just a mask of the key itself.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) bucket_index(key KT) KT {
+	if m.using_fast_range {
+		return m.fast_range_bucket_index(key)
+	}
+	if m.using_users_hash_func {
+		return KT(m.users_chosen_hash_func(key)) & m.num_buckets_m1
+	}
+	return key & m.num_buckets_m1
+}
+
+// Map a key onto `[0, num_buckets)` without needing a power of two.
+//
+// The key (or its hash) is first scrambled with a Fibonacci multiply, which puts well mixed bits at the top.
+// The top bits are then scaled down to the number of buckets with a single multiply-shift (Lemire's fast range reduction).
+func (m *SFDA_Map[KT, VT]) fast_range_bucket_index(key KT) KT {
+	h := uint64(key)
+	if m.using_users_hash_func {
+		h = m.users_chosen_hash_func(key)
+	}
+
+	hi, _ := bits.Mul64(h*0x9E3779B97F4A7C15, m.num_buckets)
+	return KT(hi)
+}
+
 // Set a key-value pair in the map.
+// If the key already exists, its value is replaced in place.
 // Will panic if something goes wrong.
 //
 // - WARNING: This function is NOT thread-safe.
 //
 //go:inline
 func (m *SFDA_Map[KT, VT]) Set(key KT, value VT) {
+	m.guard.begin_write()
+	m.set(key, value)
+	m.guard.end_write()
+}
+
+//go:inline
+func (m *SFDA_Map[KT, VT]) set(key KT, value VT) {
 	if key == 0 {
-		panic("Key cannot be 0.")
+		if !m.has_zero {
+			m.num_entries++
+		}
+		m.zero_value = value
+		m.has_zero = true
+		return
 	}
 
-	index := key & m.num_buckets_m1
-	buck := &m.buckets[index]
+	index, i := m.probe(key)
 
-	i := 0
-	found := false
-	for ; i < len(buck.keys); i++ {
-		if buck.keys[i] == key {
-			panic("not implemented")
+	// An existing key keeps its slot, so its parity bit is already correct...
+	if i != -1 {
+		m.values[index][i] = value
+		return
+	}
+
+	m.append_entry(index, key, value)
+}
+
+// Same as `Set` but also returns the previous value and whether the key already existed.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) Set_Returning_Old(key KT, value VT) (old VT, existed bool) {
+	m.guard.begin_write()
+	old, existed = m.set_returning_old(key, value)
+	m.guard.end_write()
+	return old, existed
+}
+
+//go:inline
+func (m *SFDA_Map[KT, VT]) set_returning_old(key KT, value VT) (old VT, existed bool) {
+	if key == 0 {
+		old, existed = m.zero_value, m.has_zero
+		if !existed {
+			m.num_entries++
 		}
+		m.zero_value = value
+		m.has_zero = true
+		return old, existed
 	}
 
-	mod := i % 2
-	if !found {
-		m.values[index] = append(m.values[index], value)
-		buck.keys = append(buck.keys, key)
-		mod = (len(buck.keys) - 1) % 2
+	index, i := m.probe(key)
+
+	if i != -1 {
+		old = m.values[index][i]
+		m.values[index][i] = value
+		return old, true
 	}
 
-	if mod == 1 {
-		m.extras[key/8] |= 1 << byte(key%8)
-	} else {
-		m.extras[key/8] &= ^(1 << byte(key%8))
+	m.append_entry(index, key, value)
+	return old, false
+}
+
+// Append a new entry to the end of the bucket and record the parity of its slot.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) append_entry(index KT, key KT, value VT) {
+	if m.sorted {
+		m.insert_sorted(index, key, value)
+		return
 	}
+
+	buck := &m.buckets[index]
+
+	m.values[index] = append(m.values[index], value)
+	if len(buck.keys) == cap(buck.keys) {
+		buck.keys = grow_bucket_keys(buck.keys)
+	}
+	buck.keys = append(buck.keys, key)
+	m.num_entries++
+
+	m.set_parity(key, len(buck.keys)-1)
 }
 
-// - WARNING: This function is NOT thread-safe.
+// Record which lane of its bucket `key` lives in, i.e. whether it is in an even or odd slot with the default 2 lanes.
 //
-// - NOTE: Remember that keys cannot be 0.
+//go:inline
+func (m *SFDA_Map[KT, VT]) set_parity(key KT, slot int) {
+	m.extras.set(uint64(key), slot&(m.num_lanes-1))
+}
+
+// Locate the bucket of a key and the key's slot within it, or -1 as the slot if it is not there.
 //
-// - NOTE: This function will not check if the key is 0.
+// - NOTE: Key 0 is never stored in a bucket, so callers have to check for it once the bucket misses.
+func (m *SFDA_Map[KT, VT]) probe(key KT) (KT, int) {
+	if m.plain {
+		index := key & m.num_buckets_m1
+
+		// NOTE: Keeping value type here improves performance since we do not modify the value.
+		buck := m.buckets[index]
+
+		// Buckets that have outgrown their profile enough for the kernel are left to `probe_bucket`...
+		if len(buck.keys) < SIMD_MIN_KEYS || m.find_kernel == nil {
+			i, ok := m.extras.get_two_lanes(uint64(key))
+			if !ok {
+				i = m.extras.get_sparse(uint64(key))
+			}
+
+			for ; i < len(buck.keys); i += 2 {
+				if buck.keys[i] == key {
+					return index, i
+				}
+			}
+			return index, -1
+		}
+	}
+
+	index := m.bucket_index(key)
+	return index, m.probe_bucket(m.buckets[index], key)
+}
+
+// The slot of a key within `buck`, or -1.
 //
 //go:inline
-func (m *SFDA_Map[KT, VT]) Find(key KT) int {
-	// NOTE: Keeping value type here improves performance since we do not modify the value.
-	buck := m.buckets[key&m.num_buckets_m1]
+func (m *SFDA_Map[KT, VT]) probe_bucket(buck bucket[KT], key KT) int {
+	if m.sorted {
+		return buck.search_sorted(key)
+	}
 
-	i := (m.extras[key/8] >> int(key%8)) & 1
+	if m.find_kernel != nil && len(buck.keys) >= SIMD_MIN_KEYS {
+		// The kernel also scans the zeroed padding, which only ever matches key 0...
+		i := m.find_kernel(buck.keys[:simd_padded_len[KT](len(buck.keys))], key)
+		if i >= len(buck.keys) {
+			i = -1
+		}
+		return i
+	}
+
+	i, ok := m.extras.get(uint64(key))
+	if !ok {
+		i = m.extras.get_sparse(uint64(key))
+	}
 
 	for i < len(buck.keys) {
 		if buck.keys[i] == key {
 			return i
 		}
-		i += 2
+		i += m.num_lanes
 	}
 
 	return -1
 }
 
+// Find the slot of a key within its bucket, or -1 if the key is not present.
+// The result is only meaningful when passed to `Get` together with the same key.
+//
+// Prefer `Lookup` unless the slot itself is needed.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) Find(key KT) int {
+	m.guard.begin_read()
+	_, i := m.probe(key)
+
+	if i == -1 && key == 0 && m.has_zero {
+		i = 0
+	}
+
+	m.guard.end_read()
+	return i
+}
+
+// Get the value of a key and whether it was present, in a single probe of its bucket.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) Lookup(key KT) (VT, bool) {
+	m.guard.begin_read()
+	v, ok := m.lookup(key)
+	m.guard.end_read()
+	return v, ok
+}
+
+//go:inline
+func (m *SFDA_Map[KT, VT]) lookup(key KT) (VT, bool) {
+	index, i := m.probe(key)
+	if i != -1 {
+		return m.values[index][i], true
+	}
+
+	if key == 0 && m.has_zero {
+		return m.zero_value, true
+	}
+
+	var zero VT
+	return zero, false
+}
+
+// A pointer to the value of a key, or `nil` if the key is not present.
+// It is only valid until the next `Set`, `Delete` or `Compact`, any of which may move the value.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) value_ptr(key KT) *VT {
+	index, i := m.probe(key)
+	if i != -1 {
+		return &m.values[index][i]
+	}
+
+	if key == 0 && m.has_zero {
+		return &m.zero_value
+	}
+
+	return nil
+}
+
+// Get the value of a key, or `def` if it is not present.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+//go:inline
+func (m *SFDA_Map[KT, VT]) Get_Or_Default(key KT, def VT) VT {
+	if v, ok := m.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// Get the value at the slot returned by `Find`.
+//
+// - WARNING: This function is NOT thread-safe.
+//
+//go:inline
 func (m *SFDA_Map[KT, VT]) Get(key KT, id int) VT {
-	index := key & m.num_buckets_m1
+	m.guard.begin_read()
+	v := m.get(key, id)
+	m.guard.end_read()
+	return v
+}
+
+//go:inline
+func (m *SFDA_Map[KT, VT]) get(key KT, id int) VT {
+	if key == 0 {
+		return m.zero_value
+	}
+
+	index := m.bucket_index(key)
 	return m.values[index][id]
 }
 
@@ -169,30 +482,90 @@ func (m *SFDA_Map[KT, VT]) Get(key KT, id int) VT {
 //
 // - WARNING: This function is NOT thread-safe.
 //
-// - NOTE: Remember that keys cannot be 0.
-//
-// - NOTE: This function will not check if the key is 0.
-//
 //go:inline
 func (m *SFDA_Map[KT, VT]) Delete(key KT) bool {
-	// index := key & m.num_buckets_m1
-	// buck := &m.buckets[index]
-
-	// loc := -1
-
-	// for i := 0; i < len(buck.entries); i++ {
-	// 	if buck.entries[i].key == uint64(key) {
-	// 		loc = i
-	// 		break
-	// 	}
-	// }
-
-	// // Rearrange the entire slice...
-	// if loc == -1 {
-	// 	return false
-	// }
-
-	// buck.entries = append(buck.entries[:loc], buck.entries[loc+1:]...)
-	// return true
-	panic("Not implemented.")
+	m.guard.begin_write()
+	found := m.remove(key)
+	m.guard.end_write()
+	return found
+}
+
+//go:inline
+func (m *SFDA_Map[KT, VT]) remove(key KT) bool {
+	if key == 0 {
+		existed := m.has_zero
+		if existed {
+			m.num_entries--
+		}
+		var zero VT
+		m.zero_value = zero
+		m.has_zero = false
+		return existed
+	}
+
+	index, i := m.probe(key)
+	if i == -1 {
+		return false
+	}
+
+	buck := &m.buckets[index]
+	vals := m.values[index]
+	last := len(buck.keys) - 1
+
+	// Close the gap so that the bucket stays in insertion (or sorted) order...
+	copy(buck.keys[i:], buck.keys[i+1:])
+	copy(vals[i:], vals[i+1:])
+
+	// Do not keep the removed value alive through the spare capacity, and keep the SIMD padding zeroed...
+	var zero VT
+	vals[last] = zero
+	buck.keys[last] = 0
+
+	buck.keys = buck.keys[:last]
+	m.values[index] = vals[:last]
+	m.num_entries--
+
+	if m.sorted {
+		buck.rebuild_fences()
+		return true
+	}
+
+	// Every entry after `i` has moved down one slot, so its lane has changed...
+	for j := i; j < last; j++ {
+		m.set_parity(buck.keys[j], j)
+	}
+
+	return true
+}
+
+// Remove every entry from the map while keeping the memory it has already allocated.
+//
+// Buckets keep their capacity, and only the parity bits of the keys that were stored are reset.
+// This makes a cleared map the cheapest way to start a new batch.
+//
+// - WARNING: This function is NOT thread-safe.
+func (m *SFDA_Map[KT, VT]) Clear() {
+	m.guard.begin_write()
+	defer m.guard.end_write()
+
+	for index := range m.buckets {
+		buck := &m.buckets[index]
+		for _, key := range buck.keys {
+			m.extras.set(uint64(key), 0)
+		}
+
+		// Do not keep the removed values alive through the spare capacity, and keep the SIMD padding zeroed...
+		clear(m.values[index])
+		clear(buck.keys)
+
+		buck.keys = buck.keys[:0]
+		buck.fences = buck.fences[:0]
+		m.values[index] = m.values[index][:0]
+	}
+
+	var zero VT
+	m.zero_value = zero
+	m.has_zero = false
+
+	m.num_entries = 0
 }
@@ -0,0 +1,290 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"iter"
+	"math"
+	"slices"
+	"sync"
+	"sync/atomic"
+)
+
+// One published version of a `SFDA_RCU_Map`.
+// Nothing reachable from a generation is ever written to once it has been published.
+type t_rcu_generation[KT I_Positive_Integer, VT any] struct {
+	pages       []*SFDA_Map[KT, VT]
+	num_entries int
+}
+
+// A `SFDA_Map` for many readers and few writers, in the style of read-copy-update.
+//
+// Readers load the current generation with a single atomic load and search it without ever taking a lock or seeing a half written bucket.
+// Writers are serialised, copy what they change, and publish the new generation atomically.
+//
+// A generation is a directory of `SFDA_Map` pages, see `t_key_splitter` for how a key's page is picked.
+// Everything a write does not touch is shared with the previous generation, so a write copies:
+// the page directory, the bucket directory of the one page it lands in, and the one bucket it changes.
+// The number of pages is picked so that both directories stay around the square root of the number of buckets.
+//
+// Group writes with `Update` when possible, each generation is then copied only once per batch.
+// This is also the map to use with a single writer: its reads never block, never retry and never write to shared memory.
+//
+// The pages always use sorted buckets, since the parity bitmap is updated in place and so cannot be shared between generations.
+type SFDA_RCU_Map[KT I_Positive_Integer, VT any] struct {
+	current atomic.Pointer[t_rcu_generation[KT, VT]]
+
+	write_mu sync.Mutex
+	splitter t_key_splitter[KT]
+}
+
+// Accepts the same options as `New`, they are applied to every page.
+// `With_Sorted_Buckets` is always added.
+func New_SFDA_RCU_Map[KT I_Positive_Integer, VT any](
+	expected_num_inputs KT,
+	options ...T_Option[KT, VT],
+) *SFDA_RCU_Map[KT, VT] {
+	// Aim for about as many pages as there are buckets per page...
+	num_pages := 1
+	if target := math.Sqrt(float64(expected_num_inputs) / 8); target > 1 {
+		num_pages = int(next_power_of_two(uint64(target)))
+	}
+
+	per_page := max(uint64(expected_num_inputs)/uint64(num_pages), CONCURRENT_MIN_EXPECTED_NUM_INPUTS_PER_SHARD)
+	options = append(options[:len(options):len(options)], With_Sorted_Buckets[KT, VT]())
+
+	gen := &t_rcu_generation[KT, VT]{
+		pages: make([]*SFDA_Map[KT, VT], num_pages),
+	}
+	for i := range gen.pages {
+		gen.pages[i] = New(clamp_expected_num_inputs[KT](per_page), options...)
+	}
+
+	m := &SFDA_RCU_Map[KT, VT]{
+		splitter: new_key_splitter[KT](num_pages),
+	}
+	m.current.Store(gen)
+	return m
+}
+
+// Get the value of a key and whether it was present.
+//
+// - NOTE: This function is thread-safe, and never blocks.
+func (m *SFDA_RCU_Map[KT, VT]) Lookup(key KT) (VT, bool) {
+	page, stored := m.splitter.split(key)
+	return m.current.Load().pages[page].Lookup(stored)
+}
+
+// Get the value of a key, or `def` if it is not present.
+//
+// - NOTE: This function is thread-safe, and never blocks.
+func (m *SFDA_RCU_Map[KT, VT]) Get_Or_Default(key KT, def VT) VT {
+	if v, ok := m.Lookup(key); ok {
+		return v
+	}
+	return def
+}
+
+// The number of entries stored in the map.
+//
+// - NOTE: This function is thread-safe, and never blocks.
+func (m *SFDA_RCU_Map[KT, VT]) Len() int {
+	return m.current.Load().num_entries
+}
+
+// Iterate over every key-value pair, page by page.
+//
+// The whole iteration sees one generation: writes published meanwhile are not seen, and the loop body may write to the map.
+//
+// - NOTE: This function is thread-safe, and never blocks.
+func (m *SFDA_RCU_Map[KT, VT]) All() iter.Seq2[KT, VT] {
+	return func(yield func(KT, VT) bool) {
+		gen := m.current.Load()
+		for i, page := range gen.pages {
+			for stored, v := range page.All() {
+				if !yield(m.splitter.join(i, stored), v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Iterate over every key, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe, and never blocks.
+func (m *SFDA_RCU_Map[KT, VT]) Keys() iter.Seq[KT] {
+	return func(yield func(KT) bool) {
+		for k := range m.All() {
+			if !yield(k) {
+				return
+			}
+		}
+	}
+}
+
+// Iterate over every value, in the same order as `All`.
+//
+// - NOTE: This function is thread-safe, and never blocks.
+func (m *SFDA_RCU_Map[KT, VT]) Values() iter.Seq[VT] {
+	return func(yield func(VT) bool) {
+		for _, v := range m.All() {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Set a key-value pair in the map, and publish the result.
+//
+// - NOTE: This function is thread-safe, it waits for any other writer.
+func (m *SFDA_RCU_Map[KT, VT]) Set(key KT, value VT) {
+	m.Update(func(b *T_RCU_Batch[KT, VT]) {
+		b.Set(key, value)
+	})
+}
+
+// Delete an entry from the map, publish the result, and return a boolean indicating whether the entry was found.
+//
+// - NOTE: This function is thread-safe, it waits for any other writer.
+func (m *SFDA_RCU_Map[KT, VT]) Delete(key KT) (found bool) {
+	m.Update(func(b *T_RCU_Batch[KT, VT]) {
+		found = b.Delete(key)
+	})
+	return found
+}
+
+// Apply a batch of writes and publish them as a single new generation.
+// Readers see either none or all of the batch.
+//
+// The batch must not be used once `f` returns, and `f` must write through the batch only:
+// the map's own `Set` and `Delete` would wait for `f` forever.
+// If `f` panics, nothing is published.
+//
+// - NOTE: This function is thread-safe, it waits for any other writer.
+func (m *SFDA_RCU_Map[KT, VT]) Update(f func(b *T_RCU_Batch[KT, VT])) {
+	m.write_mu.Lock()
+	defer m.write_mu.Unlock()
+
+	prev := m.current.Load()
+	b := &T_RCU_Batch[KT, VT]{
+		splitter: m.splitter,
+		prev:     prev,
+		next: &t_rcu_generation[KT, VT]{
+			pages:       append([]*SFDA_Map[KT, VT](nil), prev.pages...),
+			num_entries: prev.num_entries,
+		},
+		copied: make(map[int]*t_rcu_page_copy[KT, VT]),
+	}
+
+	f(b)
+
+	for i, c := range b.copied {
+		b.next.num_entries += c.m.Len() - prev.pages[i].Len()
+	}
+	m.current.Store(b.next)
+}
+
+// The private copy of a page being written to by a batch.
+type t_rcu_page_copy[KT I_Positive_Integer, VT any] struct {
+	m *SFDA_Map[KT, VT]
+
+	// The buckets that have been copied already, and so can be written to...
+	copied_buckets []bool
+}
+
+// A set of writes being prepared by `SFDA_RCU_Map.Update`.
+// Lookups made through the batch see its own writes.
+type T_RCU_Batch[KT I_Positive_Integer, VT any] struct {
+	splitter t_key_splitter[KT]
+	prev     *t_rcu_generation[KT, VT]
+	next     *t_rcu_generation[KT, VT]
+	copied   map[int]*t_rcu_page_copy[KT, VT]
+}
+
+// The batch's own copy of a page, made on first write.
+func (b *T_RCU_Batch[KT, VT]) page_copy(page int) *t_rcu_page_copy[KT, VT] {
+	if c, ok := b.copied[page]; ok {
+		return c
+	}
+
+	// Only the directories are copied, every bucket is still shared...
+	shared := b.prev.pages[page]
+	m := *shared
+	m.buckets = append([]bucket[KT](nil), shared.buckets...)
+	m.values = append([][]VT(nil), shared.values...)
+
+	// Readers may still be using the shared page while the batch writes to the copy, so the copy gets a guard of its own...
+	m.guard = new_misuse_guard()
+
+	c := &t_rcu_page_copy[KT, VT]{
+		m:              &m,
+		copied_buckets: make([]bool, len(m.buckets)),
+	}
+	b.copied[page] = c
+	b.next.pages[page] = c.m
+	return c
+}
+
+// Make bucket `index` of a copied page safe to write to.
+func (c *t_rcu_page_copy[KT, VT]) copy_bucket(index KT) {
+	if c.copied_buckets[index] {
+		return
+	}
+	c.copied_buckets[index] = true
+	c.m.unshare_bucket(index)
+}
+
+// Move bucket `index` to arrays of its own, with room for one more entry, so that it can be written to without touching what it used to share.
+func (m *SFDA_Map[KT, VT]) unshare_bucket(index KT) {
+	buck := &m.buckets[index]
+	n := len(buck.keys)
+
+	// A fresh allocation is already zeroed, which is what the SIMD padding needs...
+	keys := make([]KT, n, simd_padded_len[KT](n+1))
+	copy(keys, buck.keys)
+	buck.keys = keys
+	buck.fences = slices.Clone(buck.fences)
+
+	values := make([]VT, n, n+1)
+	copy(values, m.values[index])
+	m.values[index] = values
+}
+
+// Get the value of a key and whether it was present, including the writes made so far by this batch.
+func (b *T_RCU_Batch[KT, VT]) Lookup(key KT) (VT, bool) {
+	page, stored := b.splitter.split(key)
+	return b.next.pages[page].Lookup(stored)
+}
+
+// Set a key-value pair in the map.
+func (b *T_RCU_Batch[KT, VT]) Set(key KT, value VT) {
+	page, stored := b.splitter.split(key)
+	c := b.page_copy(page)
+
+	// Key 0 lives in the page itself rather than in a bucket...
+	if stored != 0 {
+		c.copy_bucket(c.m.bucket_index(stored))
+	}
+	c.m.Set(stored, value)
+}
+
+// Delete an entry from the map and return a boolean indicating whether the entry was found.
+func (b *T_RCU_Batch[KT, VT]) Delete(key KT) bool {
+	page, stored := b.splitter.split(key)
+
+	// Nothing needs copying for a key that is not there...
+	if _, ok := b.next.pages[page].Lookup(stored); !ok {
+		return false
+	}
+
+	c := b.page_copy(page)
+	if stored != 0 {
+		c.copy_bucket(c.m.bucket_index(stored))
+	}
+	return c.m.Delete(stored)
+}
@@ -0,0 +1,56 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"unsafe"
+)
+
+const (
+	// The SIMD kernels compare one block of this many bytes at a time.
+	SIMD_BLOCK_BYTES = 32
+
+	// Below this many keys per bucket the parity lanes already make `Find` cheaper than calling into a kernel.
+	SIMD_MIN_KEYS = 16
+)
+
+// How many keys of type `KT` fit in one SIMD block.
+//
+//go:inline
+func keys_per_simd_block[KT I_Positive_Integer]() int {
+	var zero KT
+	return SIMD_BLOCK_BYTES / int(unsafe.Sizeof(zero))
+}
+
+// `n` rounded up to a whole number of SIMD blocks.
+//
+//go:inline
+func simd_padded_len[KT I_Positive_Integer](n int) int {
+	per_block := keys_per_simd_block[KT]()
+	return (n + per_block - 1) / per_block * per_block
+}
+
+// The fastest kernel for `KT` on this CPU, or `nil` if the scalar path is the best there is.
+func find_kernel_for[KT I_Positive_Integer]() func([]KT, KT) int {
+	if kernel := simd_find_kernel[KT](); kernel != nil {
+		return kernel
+	}
+	return swar_find_kernel[KT]()
+}
+
+// Bucket key slices keep `cap` a whole number of SIMD blocks, and everything between `len` and `cap` zeroed.
+// A kernel may then always read `keys[:simd_padded_len(len(keys))]`.
+// Key 0 never lives in a bucket, so the padding can never be mistaken for a real key.
+//
+// Call this instead of letting `append` grow the slice, since `append` knows nothing about blocks.
+func grow_bucket_keys[KT I_Positive_Integer](keys []KT) []KT {
+	new_cap := simd_padded_len[KT](max(2*cap(keys), 1))
+
+	// A fresh allocation is already zeroed...
+	grown := make([]KT, len(keys), new_cap)
+	copy(grown, keys)
+	return grown
+}
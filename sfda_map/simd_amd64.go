@@ -0,0 +1,62 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/cpu"
+)
+
+// Decided once, at init.
+var has_avx2 = cpu.X86.HasAVX2
+
+// Warning: These functions must be called with keys satisfying the following conditions:
+// 1. len(keys) is a multiple of `keys_per_simd_block`.
+// 2. no duplicate keys.
+//
+// They return the index of the key, or -1.
+
+//go:noescape
+func simd_find_idx__uint64(keys []uint64, key uint64) int
+
+//go:noescape
+func simd_find_idx__uint32(keys []uint32, key uint32) int
+
+//go:noescape
+func simd_find_idx__uint16(keys []uint16, key uint16) int
+
+//go:noescape
+func simd_find_idx__uint8(keys []uint8, key uint8) int
+
+// The AVX2 kernel for `KT`, or `nil` if the CPU does not support AVX2.
+func simd_find_kernel[KT I_Positive_Integer]() func([]KT, KT) int {
+	if !has_avx2 {
+		return nil
+	}
+
+	var zero KT
+	switch any(zero).(type) {
+	case uint64:
+		return func(keys []KT, key KT) int {
+			return simd_find_idx__uint64(unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint64(key))
+		}
+	case uint32:
+		return func(keys []KT, key KT) int {
+			return simd_find_idx__uint32(unsafe.Slice((*uint32)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint32(key))
+		}
+	case uint16:
+		return func(keys []KT, key KT) int {
+			return simd_find_idx__uint16(unsafe.Slice((*uint16)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint16(key))
+		}
+	case uint8:
+		return func(keys []KT, key KT) int {
+			return simd_find_idx__uint8(unsafe.Slice((*uint8)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint8(key))
+		}
+	default:
+		panic("Unsupported type.")
+	}
+}
@@ -0,0 +1,149 @@
+// amd64 - go 1.23.0
+
+#include "textflag.h"
+
+// All kernels share the same register usage:
+// SI: Pointer to the slice.
+// CX: Length of the slice, in bytes.
+// AX: Key to find, then the comparison mask.
+// X0: Key to find.
+// Y0: Broadcasted key.
+// Y1: Block of keys, then the result of the SIMD comparison.
+// DI: Byte offset of the current block.
+//
+// Each iteration compares one 32-byte block, so `len(keys)` must be a multiple of the number of keys per block.
+// The first matching lane, if any, is found with BSF on the byte mask and converted back to an index.
+
+// func simd_find_idx__uint64(keys []uint64, key uint64) int
+TEXT ·simd_find_idx__uint64(SB), NOSPLIT, $0-40
+	MOVQ keys_base+0(FP), SI
+	MOVQ keys_len+8(FP), CX
+	SHLQ $3, CX
+	MOVQ key+24(FP), AX
+	MOVQ AX, X0
+	VPBROADCASTQ X0, Y0
+	XORQ DI, DI
+
+loop_uint64:
+	CMPQ DI, CX
+	JGE  fail_uint64
+	VMOVDQU   (SI)(DI*1), Y1
+	VPCMPEQQ  Y0, Y1, Y1
+	VPMOVMSKB Y1, AX
+	TESTL     AX, AX
+	JNZ       success_uint64
+	ADDQ      $32, DI
+	JMP       loop_uint64
+
+success_uint64:
+	BSFL AX, AX
+	ADDQ DI, AX
+	SHRQ $3, AX
+	VZEROUPPER
+	MOVQ AX, ret+32(FP)
+	RET
+
+fail_uint64:
+	VZEROUPPER
+	MOVQ $-1, ret+32(FP)
+	RET
+
+// func simd_find_idx__uint32(keys []uint32, key uint32) int
+TEXT ·simd_find_idx__uint32(SB), NOSPLIT, $0-40
+	MOVQ keys_base+0(FP), SI
+	MOVQ keys_len+8(FP), CX
+	SHLQ $2, CX
+	MOVL key+24(FP), AX
+	MOVQ AX, X0
+	VPBROADCASTD X0, Y0
+	XORQ DI, DI
+
+loop_uint32:
+	CMPQ DI, CX
+	JGE  fail_uint32
+	VMOVDQU   (SI)(DI*1), Y1
+	VPCMPEQD  Y0, Y1, Y1
+	VPMOVMSKB Y1, AX
+	TESTL     AX, AX
+	JNZ       success_uint32
+	ADDQ      $32, DI
+	JMP       loop_uint32
+
+success_uint32:
+	BSFL AX, AX
+	ADDQ DI, AX
+	SHRQ $2, AX
+	VZEROUPPER
+	MOVQ AX, ret+32(FP)
+	RET
+
+fail_uint32:
+	VZEROUPPER
+	MOVQ $-1, ret+32(FP)
+	RET
+
+// func simd_find_idx__uint16(keys []uint16, key uint16) int
+TEXT ·simd_find_idx__uint16(SB), NOSPLIT, $0-40
+	MOVQ    keys_base+0(FP), SI
+	MOVQ    keys_len+8(FP), CX
+	SHLQ    $1, CX
+	MOVWLZX key+24(FP), AX
+	MOVQ    AX, X0
+	VPBROADCASTW X0, Y0
+	XORQ    DI, DI
+
+loop_uint16:
+	CMPQ DI, CX
+	JGE  fail_uint16
+	VMOVDQU   (SI)(DI*1), Y1
+	VPCMPEQW  Y0, Y1, Y1
+	VPMOVMSKB Y1, AX
+	TESTL     AX, AX
+	JNZ       success_uint16
+	ADDQ      $32, DI
+	JMP       loop_uint16
+
+success_uint16:
+	BSFL AX, AX
+	ADDQ DI, AX
+	SHRQ $1, AX
+	VZEROUPPER
+	MOVQ AX, ret+32(FP)
+	RET
+
+fail_uint16:
+	VZEROUPPER
+	MOVQ $-1, ret+32(FP)
+	RET
+
+// func simd_find_idx__uint8(keys []uint8, key uint8) int
+TEXT ·simd_find_idx__uint8(SB), NOSPLIT, $0-40
+	MOVQ    keys_base+0(FP), SI
+	MOVQ    keys_len+8(FP), CX
+	MOVBLZX key+24(FP), AX
+	MOVQ    AX, X0
+	VPBROADCASTB X0, Y0
+	XORQ    DI, DI
+
+loop_uint8:
+	CMPQ DI, CX
+	JGE  fail_uint8
+	VMOVDQU   (SI)(DI*1), Y1
+	VPCMPEQB  Y0, Y1, Y1
+	VPMOVMSKB Y1, AX
+	TESTL     AX, AX
+	JNZ       success_uint8
+	ADDQ      $32, DI
+	JMP       loop_uint8
+
+success_uint8:
+	BSFL AX, AX
+	ADDQ DI, AX
+	VZEROUPPER
+	MOVQ AX, ret+32(FP)
+	RET
+
+fail_uint8:
+	VZEROUPPER
+	MOVQ $-1, ret+32(FP)
+	RET
@@ -0,0 +1,32 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"testing"
+)
+
+func Test_AVX2_Kernels(t *testing.T) {
+	if !has_avx2 {
+		t.Skip("the CPU does not support AVX2")
+	}
+
+	t.Run("uint64", func(t *testing.T) {
+		_inner__test_find_kernel[uint64](t, simd_find_idx__uint64, 1<<64-1)
+	})
+	t.Run("uint32", func(t *testing.T) {
+		_inner__test_find_kernel[uint32](t, simd_find_idx__uint32, 1<<32-1)
+	})
+	t.Run("uint16", func(t *testing.T) {
+		_inner__test_find_kernel[uint16](t, simd_find_idx__uint16, 1<<16-1)
+	})
+	t.Run("uint8", func(t *testing.T) {
+		_inner__test_find_kernel[uint8](t, simd_find_idx__uint8, 1<<8-1)
+	})
+	t.Run("kernel_for_type", func(t *testing.T) {
+		_inner__test_find_kernel[uint64](t, find_kernel_for[uint64](), 1<<64-1)
+	})
+}
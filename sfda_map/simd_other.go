@@ -0,0 +1,13 @@
+//go:build !amd64
+
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+// There are no SIMD kernels outside of amd64, `Find` falls back to the SWAR kernels.
+func simd_find_kernel[KT I_Positive_Integer]() func([]KT, KT) int {
+	return nil
+}
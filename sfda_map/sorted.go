@@ -0,0 +1,87 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"math/bits"
+	"slices"
+)
+
+const (
+	// Every `SORTED_FENCE_STRIDE`th key of a sorted bucket is copied into its fence array.
+	SORTED_FENCE_STRIDE = 8
+)
+
+// Find a key in a bucket kept sorted by `With_Sorted_Buckets`, or -1.
+//
+// The fences are a summary of the bucket: `fences[j] == keys[j*SORTED_FENCE_STRIDE]`.
+// A branchless binary search over the (small, cache friendly) fences picks the one block that can hold the key,
+// and only that block of `SORTED_FENCE_STRIDE` keys is scanned.
+func (b *bucket[KT]) search_sorted(key KT) int {
+	fences := b.fences
+	if len(fences) == 0 {
+		return -1
+	}
+
+	// Find the last fence that is <= key, or the first fence if there is none...
+	base := 0
+	n := len(fences)
+	for n > 1 {
+		half := n / 2
+		// The borrow is 1 when key < fence, so this only moves forward when fence <= key, without a branch...
+		_, borrow := bits.Sub64(uint64(key), uint64(fences[base+half]), 0)
+		base += half * int(1-borrow)
+		n -= half
+	}
+
+	start := base * SORTED_FENCE_STRIDE
+	end := min(start+SORTED_FENCE_STRIDE, len(b.keys))
+	for i := start; i < end; i++ {
+		if b.keys[i] == key {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (b *bucket[KT]) rebuild_fences() {
+	b.fences = b.fences[:0]
+	for i := 0; i < len(b.keys); i += SORTED_FENCE_STRIDE {
+		b.fences = append(b.fences, b.keys[i])
+	}
+}
+
+// Insert a new entry at its sorted position within the bucket.
+func (m *SFDA_Map[KT, VT]) insert_sorted(index KT, key KT, value VT) {
+	buck := &m.buckets[index]
+
+	i, _ := slices.BinarySearch(buck.keys, key)
+	buck.keys = slices.Insert(buck.keys, i, key)
+	m.values[index] = slices.Insert(m.values[index], i, value)
+	m.num_entries++
+
+	buck.rebuild_fences()
+}
+
+// Sorts the keys of a bucket, moving its values along with them.
+type t_bucket_sorter[KT I_Positive_Integer, VT any] struct {
+	keys   []KT
+	values []VT
+}
+
+func (s t_bucket_sorter[KT, VT]) Len() int {
+	return len(s.keys)
+}
+
+func (s t_bucket_sorter[KT, VT]) Less(i, j int) bool {
+	return s.keys[i] < s.keys[j]
+}
+
+func (s t_bucket_sorter[KT, VT]) Swap(i, j int) {
+	s.keys[i], s.keys[j] = s.keys[j], s.keys[i]
+	s.values[i], s.values[j] = s.values[j], s.values[i]
+}
@@ -0,0 +1,102 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"math/bits"
+	"unsafe"
+)
+
+// SIMD within a register: compare every key packed into a `uint64` word at once.
+//
+// Pure Go, so it works on every GOARCH.
+// It only pays off for keys narrower than a word, `uint64` keys are left to the scalar path.
+//
+// The kernels share the padding contract of the SIMD kernels, see `grow_bucket_keys`.
+// A block is a whole number of words, so a padded bucket is too.
+
+// Whether the first key of a word sits in its lowest bits.
+var swar_is_little_endian = func() bool {
+	x := uint16(1)
+	return *(*byte)(unsafe.Pointer(&x)) == 1
+}()
+
+// The index of the first lane of `words` that equals the same lane of `pattern`, or -1.
+//
+// `high` has the top bit of every lane set, and a lane is `1 << lane_shift` bits wide.
+//
+// The exact zero test is used: the cheaper borrow-based one can also flag lanes just above a real match, which is the wrong answer on big endian.
+//
+//go:inline
+func _inner__swar_first_zero_lane(words []uint64, pattern uint64, high uint64, lane_shift uint) int {
+	low := ^high
+
+	for w := range words {
+		x := words[w] ^ pattern
+
+		// A lane's top bit ends up set in `t` unless the whole lane of `x` is zero...
+		t := ((x & low) + low) | x
+		z := ^t & high
+		if z == 0 {
+			continue
+		}
+
+		lane := bits.TrailingZeros64(z) >> lane_shift
+		if !swar_is_little_endian {
+			lane = bits.LeadingZeros64(z) >> lane_shift
+		}
+		return w<<(6-lane_shift) + lane
+	}
+
+	return -1
+}
+
+// Warning: These functions must be called with keys satisfying the following conditions:
+// 1. len(keys) is a multiple of `keys_per_simd_block`.
+// 2. no duplicate keys.
+//
+// They return the index of the key, or -1.
+//
+// A key loaded as part of a word still holds its own value within its lane whatever the byte order, only the order of the lanes changes.
+// So the pattern can be built with a multiply.
+
+func swar_find_idx__uint32(keys []uint32, key uint32) int {
+	words := unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)/2)
+	return _inner__swar_first_zero_lane(words, uint64(key)*0x0000000100000001, 0x8000000080000000, 5)
+}
+
+func swar_find_idx__uint16(keys []uint16, key uint16) int {
+	words := unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)/4)
+	return _inner__swar_first_zero_lane(words, uint64(key)*0x0001000100010001, 0x8000800080008000, 4)
+}
+
+func swar_find_idx__uint8(keys []uint8, key uint8) int {
+	words := unsafe.Slice((*uint64)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)/8)
+	return _inner__swar_first_zero_lane(words, uint64(key)*0x0101010101010101, 0x8080808080808080, 3)
+}
+
+// The SWAR kernel for `KT`, or `nil` for `uint64` keys.
+func swar_find_kernel[KT I_Positive_Integer]() func([]KT, KT) int {
+	var zero KT
+	switch any(zero).(type) {
+	case uint64:
+		return nil
+	case uint32:
+		return func(keys []KT, key KT) int {
+			return swar_find_idx__uint32(unsafe.Slice((*uint32)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint32(key))
+		}
+	case uint16:
+		return func(keys []KT, key KT) int {
+			return swar_find_idx__uint16(unsafe.Slice((*uint16)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint16(key))
+		}
+	case uint8:
+		return func(keys []KT, key KT) int {
+			return swar_find_idx__uint8(unsafe.Slice((*uint8)(unsafe.Pointer(unsafe.SliceData(keys))), len(keys)), uint8(key))
+		}
+	default:
+		panic("Unsupported type.")
+	}
+}
@@ -0,0 +1,91 @@
+/*/
+ ** This software is covered by the MIT License.
+ ** See: `./LICENSE`.
+/*/
+
+package sfda_map
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+)
+
+// Fill the first `n` keys of a padded bucket with distinct non-zero keys, leaving the padding zeroed like `grow_bucket_keys` does.
+func find_kernel_test_bucket[KT I_Positive_Integer](r *rand.Rand, n int, max_key uint64) []KT {
+	keys := make([]KT, simd_padded_len[KT](n))
+	seen := map[KT]bool{}
+	for i := 0; i < n; i++ {
+		for {
+			// Keys with the top bit of their lane set are the ones a borrow-based zero test gets wrong...
+			k := KT(r.Uint64()%max_key + 1)
+			if r.Intn(4) == 0 {
+				k = KT(max_key) - KT(r.Intn(4))
+			}
+			if !seen[k] {
+				seen[k] = true
+				keys[i] = k
+				break
+			}
+		}
+	}
+	return keys
+}
+
+func _inner__test_find_kernel[KT I_Positive_Integer](t *testing.T, find func([]KT, KT) int, max_key uint64) {
+	r := rand.New(rand.NewSource(1))
+
+	// Every length up to a few blocks, so most of them are not a whole number of words...
+	for n := 0; n <= 3*keys_per_simd_block[KT](); n++ {
+		for trial := 0; trial < 8; trial++ {
+			keys := find_kernel_test_bucket[KT](r, n, max_key)
+
+			// Every hit, down to the last lane of the last word holding a key...
+			for i := 0; i < n; i++ {
+				if got := find(keys, keys[i]); got != i {
+					t.Fatalf("n=%d: key %d at %d found at %d", n, keys[i], i, got)
+				}
+			}
+
+			// A miss, which only ever finds the zeroed padding when looking for key 0...
+			for _, key := range []KT{KT(max_key), KT(max_key) ^ 1, KT(max_key>>1 + 1), 1, 0} {
+				want := slices.Index(keys, key)
+				if want >= n {
+					want = n
+				}
+				got := find(keys, key)
+				if got >= n {
+					got = n
+				}
+				if want == -1 && got != -1 || want != -1 && got != want {
+					t.Fatalf("n=%d: key %d found at %d, expected %d", n, key, got, want)
+				}
+			}
+		}
+	}
+
+	// A hit in the very last lane of a full bucket...
+	n := 2 * keys_per_simd_block[KT]()
+	keys := find_kernel_test_bucket[KT](r, n, max_key)
+	if got := find(keys, keys[n-1]); got != n-1 {
+		t.Fatalf("last lane found at %d", got)
+	}
+}
+
+func Test_SWAR_Kernels(t *testing.T) {
+	t.Run("uint32", func(t *testing.T) {
+		_inner__test_find_kernel[uint32](t, swar_find_idx__uint32, 1<<32-1)
+	})
+	t.Run("uint16", func(t *testing.T) {
+		_inner__test_find_kernel[uint16](t, swar_find_idx__uint16, 1<<16-1)
+	})
+	t.Run("uint8", func(t *testing.T) {
+		_inner__test_find_kernel[uint8](t, swar_find_idx__uint8, 1<<8-1)
+	})
+	t.Run("kernel_for_type", func(t *testing.T) {
+		if swar_find_kernel[uint64]() != nil {
+			t.Fatal("uint64 keys should have no SWAR kernel")
+		}
+		_inner__test_find_kernel[uint16](t, swar_find_kernel[uint16](), 1<<16-1)
+	})
+}
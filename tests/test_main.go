@@ -6,9 +6,14 @@
 package sfda_map_tests
 
 import (
+	"fmt"
 	"log"
+	"maps"
 	"math/rand"
 	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nacioboi/go_sfda_map/sfda_map"
@@ -66,11 +71,11 @@ func Bench_Linear_SFDA_Map_Get(sfda *sfda_map.SFDA_Map[uint64, uint64], n uint64
 	t = 0
 	start = time.Now()
 	for i := uint64(0); i < n; i++ {
-		idx := sfda.Find(i + 1)
-		if idx == -1 {
+		x, ok := sfda.Lookup(i + 1)
+		if !ok {
 			log.Fatalf("Key %d not found.\n", i+1)
 		}
-		t += sfda.Get(i+1, idx)
+		t += x
 	}
 	since := time.Since(start)
 	return Test_Result{
@@ -112,11 +117,31 @@ func Bench_Random_SFDA_Map_Get(sfda *sfda_map.SFDA_Map[uint64, uint64], random_k
 	start = time.Now()
 	for i := 0; i < len(random_keys); i++ {
 		key := random_keys[i]
-		idx := sfda.Find(key)
-		if idx == -1 {
+		x, ok := sfda.Lookup(key)
+		if !ok {
 			log.Fatalf("Key %d not found.\n", key)
 		}
-		t += sfda.Get(key, idx)
+		t += x
+	}
+	since := time.Since(start)
+	return Test_Result{
+		Elapsed_Time: since.Microseconds(),
+		Checksum:     t,
+	}
+}
+
+func Bench_Random_SFDA_Map_Get_Many(sfda *sfda_map.SFDA_Map[uint64, uint64], random_keys []uint64) Test_Result {
+	out := make([]uint64, len(random_keys))
+	found := make([]bool, len(random_keys))
+
+	t = 0
+	start = time.Now()
+	sfda.Get_Many(random_keys, out, found)
+	for i := 0; i < len(random_keys); i++ {
+		if !found[i] {
+			log.Fatalf("Key %d not found.\n", random_keys[i])
+		}
+		t += out[i]
 	}
 	since := time.Since(start)
 	return Test_Result{
@@ -230,69 +255,76 @@ func Bench_Mem_Usage_SFDA_Map(f func() *sfda_map.SFDA_Map[uint64, uint64], n uin
 // 	fmt.Println("Built-in map read time:", end_read.Sub(start).Microseconds())
 // }
 
-// func Bench_Concurrent_Access_SFDA_Map(sfda *sfda_map.SFDA_Map[uint64, uint64], n uint64) {
-// 	var end_write, end_read time.Time
-
-// 	var wg sync.WaitGroup
-// 	numGoroutines := 100
-// 	operationsPerGoroutine := n / uint64(numGoroutines)
+func Bench_Concurrent_Access_SFDA_Map(sfda *sfda_map.SFDA_Concurrent_Map[uint64, uint64], n uint64) {
+	var start_write, end_write, start_read, end_read time.Time
 
-// 	// Writes
-// 	wg.Add(numGoroutines)
-// 	start = time.Now()
-// 	for i := 0; i < numGoroutines; i++ {
-// 		go func(id int) {
-// 			defer wg.Done()
-// 			for j := uint64(0); j < operationsPerGoroutine; j++ {
-// 				key := uint64(id)*operationsPerGoroutine + j + 1
-// 				sfda.Set(key, key)
-// 			}
-// 		}(i)
-// 	}
-// 	wg.Wait()
-// 	end_write = time.Now()
+	var wg sync.WaitGroup
+	numGoroutines := 100
+	operationsPerGoroutine := n / uint64(numGoroutines)
 
-// 	// Reads
-// 	wg.Add(numGoroutines)
-// 	start = time.Now()
-// 	for i := 0; i < numGoroutines; i++ {
-// 		go func(id int) {
-// 			defer wg.Done()
-// 			for j := uint64(0); j < operationsPerGoroutine; j++ {
-// 				key := uint64(id)*operationsPerGoroutine + j + 1
-// 				sfda.Get(key)
-// 			}
-// 		}(i)
-// 	}
-// 	wg.Wait()
-// 	end_read = time.Now()
+	// Writes
+	wg.Add(numGoroutines)
+	start_write = time.Now()
+	for i := 0; i < numGoroutines; i++ {
+		go func(id int) {
+			defer wg.Done()
+			for j := uint64(0); j < operationsPerGoroutine; j++ {
+				key := uint64(id)*operationsPerGoroutine + j + 1
+				sfda.Set(key, key)
+			}
+		}(i)
+	}
+	wg.Wait()
+	end_write = time.Now()
 
-// 	fmt.Println("SFDA map write time:", end_write.Sub(start).Microseconds())
-// 	fmt.Println("SFDA map read time:", end_read.Sub(start).Microseconds())
-// }
+	// Reads
+	wg.Add(numGoroutines)
+	start_read = time.Now()
+	for i := 0; i < numGoroutines; i++ {
+		go func(id int) {
+			defer wg.Done()
+			for j := uint64(0); j < operationsPerGoroutine; j++ {
+				key := uint64(id)*operationsPerGoroutine + j + 1
+				if x, ok := sfda.Lookup(key); !ok || x != key {
+					log.Fatalf("Key %d not found.\n", key)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	end_read = time.Now()
 
-// func Bench_SFDA_Resizable_Map(f *os.File, n uint64) {
-// 	start = time.Now()
-// 	sfda_resizable := sfda_map.Make_SFDA_Resizable_Map(uint32(1024), 4)
-// 	sfda_resizable.Start_Background_Services()
-// 	defer sfda_resizable.Stop_Background_Services()
+	fmt.Println("SFDA map write time:", end_write.Sub(start_write).Microseconds())
+	fmt.Println("SFDA map read time:", end_read.Sub(start_read).Microseconds())
+}
 
-// 	for i := uint64(0); i < n; i++ {
-// 		sfda_resizable.Set(i+1, i)
-// 	}
-// 	fmt.Println("SFDA resizable map set time:", time.Since(start))
+func Bench_Linear_SFDA_Resizable_Map_Set(sfda *sfda_map.SFDA_Resizable_Map[uint64, uint64], n uint64) Test_Result {
+	start = time.Now()
+	for i := uint64(0); i < n; i++ {
+		sfda.Set(i+1, i)
+	}
+	since := time.Since(start)
+	return Test_Result{
+		Elapsed_Time: since.Microseconds(),
+	}
+}
 
-// 	t = 0
-// 	start = time.Now()
-// 	//pprof.StartCPUProfile(f)
-// 	for i := uint64(0); i < n; i++ {
-// 		x, _ := sfda_resizable.Get(i + 1)
-// 		t += x.(uint64)
-// 	}
-// 	//pprof.StopCPUProfile()
-// 	fmt.Println("SFDA resizable map get time:", time.Since(start))
-// 	fmt.Println("Sum:", t)
-// }
+func Bench_Linear_SFDA_Resizable_Map_Get(sfda *sfda_map.SFDA_Resizable_Map[uint64, uint64], n uint64) Test_Result {
+	t = 0
+	start = time.Now()
+	for i := uint64(0); i < n; i++ {
+		x, ok := sfda.Lookup(i + 1)
+		if !ok {
+			log.Fatalf("Key %d not found.\n", i+1)
+		}
+		t += x
+	}
+	since := time.Since(start)
+	return Test_Result{
+		Elapsed_Time: since.Microseconds(),
+		Checksum:     t,
+	}
+}
 
 func Test_Consistency(n uint64) {
 	sfda_map := sfda_map.New[uint64, uint64](n)
@@ -311,3 +343,941 @@ func Test_Consistency(n uint64) {
 		}
 	}
 }
+
+func Test_Lookup(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i+1, i)
+	}
+
+	for i := uint64(0); i < n; i++ {
+		x, ok := sfda_map.Lookup(i + 1)
+		if !ok {
+			log.Fatalf("Key %d not found.\n", i+1)
+		}
+		if x != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i+1, x)
+		}
+		if sfda_map.Get_Or_Default(i+1, n) != i {
+			log.Fatalf("Present key %d returned the default.\n", i+1)
+		}
+	}
+
+	// Missing keys, including key 0 until it is set...
+	if _, ok := sfda_map.Lookup(n + 1); ok {
+		log.Fatalf("Missing key %d was found.\n", n+1)
+	}
+	if sfda_map.Get_Or_Default(n+1, n) != n {
+		log.Fatalf("Missing key %d did not return the default.\n", n+1)
+	}
+	if _, ok := sfda_map.Lookup(0); ok {
+		log.Fatalf("Missing key 0 was found.\n")
+	}
+	sfda_map.Set(0, n)
+	if x, ok := sfda_map.Lookup(0); !ok || x != n {
+		log.Fatalf("Wrong value for key 0. Got %d\n", x)
+	}
+	if sfda_map.Get_Or_Default(0, 0) != n {
+		log.Fatalf("Key 0 returned the default.\n")
+	}
+}
+
+func Test_Overwrite(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i+1, i)
+	}
+
+	for i := uint64(0); i < n; i++ {
+		old, existed := sfda_map.Set_Returning_Old(i+1, i*2)
+		if !existed {
+			log.Fatalf("Key %d should have existed.\n", i+1)
+		}
+		if old != i {
+			log.Fatalf("Wrong old value for key %d. Got %d\n", i+1, old)
+		}
+	}
+
+	for i := uint64(0); i < n; i++ {
+		idx := sfda_map.Find(i + 1)
+		if idx == -1 {
+			log.Fatalf("Key %d not found.\n", i+1)
+		}
+		if sfda_map.Get(i+1, idx) != i*2 {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i+1, sfda_map.Get(i+1, idx))
+		}
+	}
+}
+
+func Test_Deletion(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i+1, i)
+	}
+
+	// Delete every other key...
+	for i := uint64(0); i < n; i += 2 {
+		if !sfda_map.Delete(i + 1) {
+			log.Fatalf("Key %d should have been deleted.\n", i+1)
+		}
+		if sfda_map.Delete(i + 1) {
+			log.Fatalf("Key %d was deleted twice.\n", i+1)
+		}
+	}
+
+	for i := uint64(0); i < n; i++ {
+		idx := sfda_map.Find(i + 1)
+		if i%2 == 0 {
+			if idx != -1 {
+				log.Fatalf("Key %d should not be found.\n", i+1)
+			}
+			continue
+		}
+		if idx == -1 {
+			log.Fatalf("Key %d not found.\n", i+1)
+		}
+		if sfda_map.Get(i+1, idx) != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i+1, sfda_map.Get(i+1, idx))
+		}
+	}
+}
+
+// `n` must be a power of two, so that the map holds exactly what its profile targets at `n` entries.
+func Test_Len(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	expect := func(num_entries int, load_factor float64) {
+		if sfda_map.Len() != num_entries {
+			log.Fatalf("Expected %d entries, got %d.\n", num_entries, sfda_map.Len())
+		}
+		if sfda_map.Enquire_Load_Factor() != load_factor {
+			log.Fatalf("Expected a load factor of %f, got %f.\n", load_factor, sfda_map.Enquire_Load_Factor())
+		}
+	}
+	expect(0, 0)
+
+	// New keys count, overwrites do not...
+	for i := uint64(1); i <= n/2; i++ {
+		sfda_map.Set(i, i)
+	}
+	expect(int(n/2), 0.5)
+	for i := uint64(1); i <= n/2; i++ {
+		sfda_map.Set(i, i+1)
+		sfda_map.Set_Returning_Old(i, i+2)
+	}
+	expect(int(n/2), 0.5)
+
+	// Outgrowing the expected number of inputs shows in the load factor...
+	for i := uint64(1); i <= 2*n; i++ {
+		sfda_map.Set(i, i)
+	}
+	expect(int(2*n), 2)
+
+	// Only deleting a present key counts...
+	for i := uint64(1); i <= n; i++ {
+		sfda_map.Delete(i)
+		sfda_map.Delete(i)
+	}
+	expect(int(n), 1)
+
+	// Key 0 counts like any other, although it never takes a bucket slot...
+	sfda_map.Set(0, 1)
+	sfda_map.Set(0, 2)
+	if sfda_map.Len() != int(n)+1 {
+		log.Fatalf("Expected %d entries, got %d.\n", n+1, sfda_map.Len())
+	}
+	sfda_map.Delete(0)
+	sfda_map.Delete(0)
+	expect(int(n), 1)
+
+	sfda_map.Set(0, 1)
+	sfda_map.Clear()
+	expect(0, 0)
+}
+
+func Test_Zero_Key(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	if sfda_map.Find(0) != -1 {
+		log.Fatalf("Key 0 found in an empty map.\n")
+	}
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i, i+1)
+	}
+
+	for i := uint64(0); i < n; i++ {
+		idx := sfda_map.Find(i)
+		if idx == -1 {
+			log.Fatalf("Key %d not found.\n", i)
+		}
+		if sfda_map.Get(i, idx) != i+1 {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, sfda_map.Get(i, idx))
+		}
+	}
+
+	if !sfda_map.Delete(0) {
+		log.Fatalf("Key 0 should have been deleted.\n")
+	}
+	if sfda_map.Find(0) != -1 {
+		log.Fatalf("Key 0 found after deletion.\n")
+	}
+}
+
+func Test_Hash_Func(n uint64) {
+	const stride = 1024
+
+	sfda_map := sfda_map.New[uint64, uint64](
+		n,
+		sfda_map.With_Hash_Func[uint64, uint64](func(key uint64) uint64 {
+			return key / stride
+		}),
+	)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i*stride, i)
+	}
+
+	for i := uint64(0); i < n; i++ {
+		idx := sfda_map.Find(i * stride)
+		if idx == -1 {
+			log.Fatalf("Key %d not found.\n", i*stride)
+		}
+		if sfda_map.Get(i*stride, idx) != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i*stride, sfda_map.Get(i*stride, idx))
+		}
+	}
+}
+
+func Test_Builtin_Hash_Funcs(n uint64) {
+	const stride = 1024
+
+	hash_funcs := []func(uint64) uint64{
+		sfda_map.Hash_Identity[uint64](),
+		sfda_map.Hash_Fibonacci[uint64](),
+		sfda_map.Hash_Murmur3_Finalizer[uint64](),
+		sfda_map.Hash_Seeded[uint64](sfda_map.New_Random_Hash_Seed()),
+	}
+
+	for _, f := range hash_funcs {
+		sfda_map := sfda_map.New[uint64, uint64](n, sfda_map.With_Hash_Func[uint64, uint64](f))
+
+		for i := uint64(0); i < n; i++ {
+			sfda_map.Set(i*stride, i)
+		}
+
+		for i := uint64(0); i < n; i++ {
+			idx := sfda_map.Find(i * stride)
+			if idx == -1 {
+				log.Fatalf("Key %d not found.\n", i*stride)
+			}
+			if sfda_map.Get(i*stride, idx) != i {
+				log.Fatalf("Wrong value for key %d. Got %d\n", i*stride, sfda_map.Get(i*stride, idx))
+			}
+		}
+	}
+}
+
+func Test_Iteration(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i, i+1)
+	}
+
+	collected := maps.Collect(sfda_map.All())
+	if uint64(len(collected)) != n {
+		log.Fatalf("Expected %d entries, got %d.\n", n, len(collected))
+	}
+	for i := uint64(0); i < n; i++ {
+		if collected[i] != i+1 {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, collected[i])
+		}
+	}
+
+	keys := slices.Sorted(sfda_map.Keys())
+	for i := uint64(0); i < n; i++ {
+		if keys[i] != i {
+			log.Fatalf("Wrong key at position %d. Got %d\n", i, keys[i])
+		}
+	}
+
+	values := slices.Sorted(sfda_map.Values())
+	for i := uint64(0); i < n; i++ {
+		if values[i] != i+1 {
+			log.Fatalf("Wrong value at position %d. Got %d\n", i, values[i])
+		}
+	}
+
+	// Breaking early must stop the iteration...
+	count := 0
+	for range sfda_map.All() {
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 {
+		log.Fatalf("Iteration did not stop early.\n")
+	}
+}
+
+func Test_Clear(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for round := uint64(0); round < 3; round++ {
+		for i := uint64(0); i < n; i++ {
+			sfda_map.Set(i, i+round)
+		}
+
+		for i := uint64(0); i < n; i++ {
+			x, ok := sfda_map.Lookup(i)
+			if !ok {
+				log.Fatalf("Key %d not found.\n", i)
+			}
+			if x != i+round {
+				log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+			}
+		}
+
+		sfda_map.Clear()
+
+		if sfda_map.Len() != 0 {
+			log.Fatalf("Expected an empty map, got %d entries.\n", sfda_map.Len())
+		}
+		for i := uint64(0); i < n; i++ {
+			if _, ok := sfda_map.Lookup(i); ok {
+				log.Fatalf("Key %d found after clearing.\n", i)
+			}
+		}
+	}
+}
+
+func Test_Resizable(n uint64) {
+	sfda_map := sfda_map.New_SFDA_Resizable_Map[uint64, uint64](16)
+	initial_num_buckets := sfda_map.Enquire_Number_Of_Buckets()
+
+	// Grow well past the initial size...
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i, i+1)
+	}
+	grown_num_buckets := sfda_map.Enquire_Number_Of_Buckets()
+	if grown_num_buckets <= initial_num_buckets {
+		log.Fatalf("Expected the map to grow past %d buckets, got %d.\n", initial_num_buckets, grown_num_buckets)
+	}
+	if uint64(sfda_map.Len()) != n {
+		log.Fatalf("Expected %d entries, got %d.\n", n, sfda_map.Len())
+	}
+	for i := uint64(0); i < n; i++ {
+		x, ok := sfda_map.Lookup(i)
+		if !ok {
+			log.Fatalf("Key %d not found.\n", i)
+		}
+		if x != i+1 {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+		}
+	}
+
+	// Then shrink back down...
+	for i := uint64(0); i < n; i++ {
+		if i%16 != 0 && !sfda_map.Delete(i) {
+			log.Fatalf("Key %d should have been deleted.\n", i)
+		}
+	}
+	for i := uint64(0); i < n; i++ {
+		_, ok := sfda_map.Lookup(i)
+		if ok != (i%16 == 0) {
+			log.Fatalf("Wrong presence for key %d.\n", i)
+		}
+	}
+	if sfda_map.Enquire_Number_Of_Buckets() >= grown_num_buckets {
+		log.Fatalf("Expected the map to shrink below %d buckets, got %d.\n", grown_num_buckets, sfda_map.Enquire_Number_Of_Buckets())
+	}
+
+	Test_Resizable_Skewed(n)
+}
+
+// Keys that all land in the same bucket are still migrated a few at a time, over many `Set`s.
+func Test_Resizable_Skewed(n uint64) {
+	skewed := sfda_map.New_SFDA_Resizable_Map[uint64, uint64](16)
+	num_resizes := 0
+	for i := uint64(1); i <= n; i++ {
+		was_resizing := skewed.Enquire_Is_Resizing()
+		len_before := uint64(skewed.Len())
+		skewed.Set(i<<32, i)
+		if was_resizing || !skewed.Enquire_Is_Resizing() {
+			continue
+		}
+
+		// A resize just started, every later `Set` moves at most `RESIZABLE_ENTRIES_PER_STEP` entries...
+		num_resizes++
+		num_steps := uint64(0)
+		for ; skewed.Enquire_Is_Resizing() && i < n; num_steps++ {
+			i++
+			skewed.Set(i<<32, i)
+		}
+		if !skewed.Enquire_Is_Resizing() && num_steps*sfda_map.RESIZABLE_ENTRIES_PER_STEP < len_before {
+			log.Fatalf("Migrated %d entries in only %d steps.\n", len_before, num_steps)
+		}
+	}
+	if num_resizes == 0 {
+		log.Fatalf("Expected the map to resize.\n")
+	}
+	for i := uint64(1); i <= n; i++ {
+		if x, ok := skewed.Lookup(i << 32); !ok || x != i {
+			log.Fatalf("Wrong value for key %d.\n", i<<32)
+		}
+	}
+}
+
+func Test_Fast_Range_Reduction(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](
+		n,
+		sfda_map.With_Fast_Range_Reduction[uint64, uint64](),
+	)
+
+	expected_num_buckets := (n + 7) / 8
+	if uint64(sfda_map.Enquire_Number_Of_Buckets()) != expected_num_buckets {
+		log.Fatalf("Expected %d buckets, got %d.\n", expected_num_buckets, sfda_map.Enquire_Number_Of_Buckets())
+	}
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i*1024, i)
+	}
+
+	for i := uint64(0); i < n; i++ {
+		x, ok := sfda_map.Lookup(i * 1024)
+		if !ok {
+			log.Fatalf("Key %d not found.\n", i*1024)
+		}
+		if x != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i*1024, x)
+		}
+	}
+}
+
+func Test_Compact(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i, i)
+	}
+
+	sfda_map.Compact()
+
+	// Overwrite, delete and insert after compacting...
+	for i := uint64(0); i < n; i++ {
+		switch i % 3 {
+		case 0:
+			sfda_map.Set(i, i+1)
+		case 1:
+			sfda_map.Delete(i)
+		}
+		sfda_map.Set(n+i, n+i)
+	}
+
+	sfda_map.Compact()
+
+	for i := uint64(0); i < 2*n; i++ {
+		x, ok := sfda_map.Lookup(i)
+		var expected uint64
+		switch {
+		case i >= n:
+			expected = i
+		case i%3 == 0:
+			expected = i + 1
+		case i%3 == 1:
+			if ok {
+				log.Fatalf("Key %d found after deletion.\n", i)
+			}
+			continue
+		default:
+			expected = i
+		}
+		if !ok {
+			log.Fatalf("Key %d not found.\n", i)
+		}
+		if x != expected {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+		}
+	}
+}
+
+func Test_Frozen(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](n)
+
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i*1024, i)
+	}
+
+	frozen := sfda_map.Freeze()
+
+	if uint64(frozen.Len()) != n {
+		log.Fatalf("Expected %d entries, got %d.\n", n, frozen.Len())
+	}
+	for i := uint64(0); i < n; i++ {
+		x, ok := frozen.Lookup(i * 1024)
+		if !ok {
+			log.Fatalf("Key %d not found.\n", i*1024)
+		}
+		if x != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i*1024, x)
+		}
+		if _, ok := frozen.Lookup(i*1024 + 1); ok {
+			log.Fatalf("Key %d should not be found.\n", i*1024+1)
+		}
+	}
+}
+
+func Test_Parity_Lanes(n uint64) {
+	for _, lanes := range []sfda_map.T_Parity_Lanes{sfda_map.PARITY_LANES__2, sfda_map.PARITY_LANES__4, sfda_map.PARITY_LANES__8} {
+		sfda_map := sfda_map.New[uint64, uint64](
+			n,
+			sfda_map.With_Performance_Profile[uint64, uint64](sfda_map.PERFORMANCE_PROFILE__32_ENTRIES_PER_BUCKET),
+			sfda_map.With_Parity_Lanes[uint64, uint64](lanes),
+		)
+
+		for i := uint64(0); i < n; i++ {
+			sfda_map.Set(i, i)
+		}
+
+		// Deleting shifts the lanes of every later entry in the bucket...
+		for i := uint64(0); i < n; i += 3 {
+			sfda_map.Delete(i)
+		}
+
+		for i := uint64(0); i < n; i++ {
+			x, ok := sfda_map.Lookup(i)
+			if ok != (i%3 != 0) {
+				log.Fatalf("Wrong presence for key %d.\n", i)
+			}
+			if ok && x != i {
+				log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+			}
+		}
+	}
+}
+
+func Test_Sorted_Buckets(n uint64) {
+	sfda_map := sfda_map.New[uint64, uint64](
+		n,
+		sfda_map.With_Performance_Profile[uint64, uint64](sfda_map.PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET),
+		sfda_map.With_Sorted_Buckets[uint64, uint64](),
+	)
+
+	// Insert in reverse so that every insertion lands at the front of its bucket...
+	for i := n; i > 0; i-- {
+		sfda_map.Set(i, i)
+	}
+	for i := uint64(1); i <= n; i += 5 {
+		sfda_map.Delete(i)
+	}
+
+	for i := uint64(1); i <= n; i++ {
+		x, ok := sfda_map.Lookup(i)
+		if ok != ((i-1)%5 != 0) {
+			log.Fatalf("Wrong presence for key %d.\n", i)
+		}
+		if ok && x != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+		}
+	}
+}
+
+func _inner__test_wide_buckets[KT sfda_map.I_Positive_Integer](n KT) {
+	sfda_map := sfda_map.New[KT, KT](
+		n,
+		sfda_map.With_Performance_Profile[KT, KT](sfda_map.PERFORMANCE_PROFILE__64_ENTRIES_PER_BUCKET),
+	)
+
+	for i := KT(1); i < n; i++ {
+		sfda_map.Set(i, i)
+	}
+	for i := KT(1); i < n; i += 3 {
+		sfda_map.Delete(i)
+	}
+	sfda_map.Compact()
+
+	for i := KT(1); i < n; i++ {
+		x, ok := sfda_map.Lookup(i)
+		if ok != ((i-1)%3 != 0) {
+			log.Fatalf("Wrong presence for key %d.\n", i)
+		}
+		if ok && x != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+		}
+	}
+
+	// Key 0 must never match the zeroed padding past the end of a bucket...
+	if _, ok := sfda_map.Lookup(0); ok {
+		log.Fatalf("Key 0 should not be present.\n")
+	}
+}
+
+// Buckets big enough to be searched with SIMD, for every key width.
+func Test_Wide_Buckets(n uint16) {
+	_inner__test_wide_buckets(uint64(n))
+	_inner__test_wide_buckets(uint32(n))
+	_inner__test_wide_buckets(n)
+	_inner__test_wide_buckets(uint8(128))
+}
+
+func Test_Get_Many(n uint64) {
+	for _, batched := range []bool{false, true} {
+		options := []sfda_map.T_Option[uint64, uint64]{}
+		if batched {
+			options = append(options, sfda_map.With_Experimental_Batched_Gets[uint64, uint64]())
+		}
+		sfda_map := sfda_map.New[uint64, uint64](n, options...)
+
+		// Only the even keys are present, key 0 included...
+		for i := uint64(0); i < n; i += 2 {
+			sfda_map.Set(i, i+1)
+		}
+
+		keys := make([]uint64, 2*n)
+		for i := range keys {
+			keys[i] = uint64(i)
+		}
+		out := make([]uint64, len(keys))
+		found := make([]bool, len(keys))
+		sfda_map.Get_Many(keys, out, found)
+
+		for i, key := range keys {
+			want_found := key < n && key%2 == 0
+			if found[i] != want_found {
+				log.Fatalf("Wrong presence for key %d (batched: %v).\n", key, batched)
+			}
+			if want_found && out[i] != key+1 {
+				log.Fatalf("Wrong value for key %d (batched: %v). Got %d\n", key, batched, out[i])
+			}
+			if !want_found && out[i] != 0 {
+				log.Fatalf("Missing key %d should give the zero value (batched: %v).\n", key, batched)
+			}
+		}
+	}
+}
+
+func Test_New_From_Slices(n uint64) {
+	keys := make([]uint64, n)
+	values := make([]uint64, n)
+	for i := uint64(0); i < n; i++ {
+		// Key 0 included...
+		keys[i] = i
+		values[i] = i * 3
+	}
+
+	for _, sorted := range []bool{false, true} {
+		options := []sfda_map.T_Option[uint64, uint64]{}
+		if sorted {
+			options = append(options, sfda_map.With_Sorted_Buckets[uint64, uint64]())
+		}
+
+		sfda_map, err := sfda_map.New_From_Slices(keys, values, options...)
+		if err != nil {
+			log.Fatalf("Bulk load failed: %v\n", err)
+		}
+		if sfda_map.Len() != int(n) {
+			log.Fatalf("Wrong length after bulk load. Got %d\n", sfda_map.Len())
+		}
+		for i := uint64(0); i < n; i++ {
+			x, ok := sfda_map.Lookup(i)
+			if !ok || x != i*3 {
+				log.Fatalf("Wrong value for key %d (sorted: %v). Got %d\n", i, sorted, x)
+			}
+		}
+
+		// The map keeps working as usual afterwards...
+		sfda_map.Set(n, 1)
+		sfda_map.Delete(1)
+		if x, ok := sfda_map.Lookup(n); !ok || x != 1 {
+			log.Fatalf("Set after bulk load failed.\n")
+		}
+		if _, ok := sfda_map.Lookup(1); ok {
+			log.Fatalf("Delete after bulk load failed.\n")
+		}
+	}
+
+	if _, err := sfda_map.New_From_Slices([]uint64{1, 2, 1}, []uint64{1, 2, 3}); err == nil {
+		log.Fatalf("Duplicate keys should be reported.\n")
+	}
+	if _, err := sfda_map.New_From_Slices([]uint64{1, 2}, []uint64{1}); err == nil {
+		log.Fatalf("Mismatched lengths should be reported.\n")
+	}
+
+	// `uint8` keys leave room for two buckets of 64 entries, but not of 128...
+	narrow_keys := []uint8{0, 1, 2, 255}
+	narrow_values := []uint8{1, 2, 3, 4}
+	narrow, err := sfda_map.New_From_Slices(narrow_keys, narrow_values, sfda_map.With_Performance_Profile[uint8, uint8](sfda_map.PERFORMANCE_PROFILE__64_ENTRIES_PER_BUCKET))
+	if err != nil {
+		log.Fatalf("Bulk load of uint8 keys failed: %v\n", err)
+	}
+	if x, ok := narrow.Lookup(255); !ok || x != 4 {
+		log.Fatalf("Wrong value for key 255. Got %d\n", x)
+	}
+	if _, err := sfda_map.New_From_Slices(narrow_keys, narrow_values, sfda_map.With_Performance_Profile[uint8, uint8](sfda_map.PERFORMANCE_PROFILE__128_ENTRIES_PER_BUCKET)); err == nil {
+		log.Fatalf("A profile too wide for the key type should be reported.\n")
+	}
+}
+
+// `n` must be too few inputs for two buckets of 64 entries.
+func Test_Too_Few_Inputs(n uint64) {
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				log.Fatalf("Too few inputs for two buckets should panic.\n")
+			}
+		}()
+		sfda_map.New[uint64, uint64](n, sfda_map.With_Performance_Profile[uint64, uint64](sfda_map.PERFORMANCE_PROFILE__64_ENTRIES_PER_BUCKET))
+	}()
+
+	// Fast range reduction takes any number of buckets, even one...
+	sfda_map := sfda_map.New[uint64, uint64](
+		n,
+		sfda_map.With_Performance_Profile[uint64, uint64](sfda_map.PERFORMANCE_PROFILE__64_ENTRIES_PER_BUCKET),
+		sfda_map.With_Fast_Range_Reduction[uint64, uint64](),
+	)
+	for i := uint64(0); i < n; i++ {
+		sfda_map.Set(i, i)
+	}
+	for i := uint64(0); i < n; i++ {
+		if x, ok := sfda_map.Lookup(i); !ok || x != i {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+		}
+	}
+}
+
+func Test_Concurrent(n uint64) {
+	sfda_map := sfda_map.New_SFDA_Concurrent_Map[uint64, uint64](n, 16)
+
+	var wg sync.WaitGroup
+	num_goroutines := uint64(8)
+
+	// Every goroutine writes its own keys, and deletes every third one, while reading back what it wrote...
+	wg.Add(int(num_goroutines))
+	for g := uint64(0); g < num_goroutines; g++ {
+		go func(g uint64) {
+			defer wg.Done()
+			for key := g; key < n; key += num_goroutines {
+				sfda_map.Set(key, key*2)
+				if x, ok := sfda_map.Lookup(key); !ok || x != key*2 {
+					log.Fatalf("Key %d not found right after being set.\n", key)
+				}
+				if key%3 == 0 {
+					sfda_map.Delete(key)
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	expected := make(map[uint64]uint64)
+	for key := uint64(0); key < n; key++ {
+		if key%3 != 0 {
+			expected[key] = key * 2
+		}
+	}
+	if sfda_map.Len() != len(expected) {
+		log.Fatalf("Wrong length. Got %d, expected %d\n", sfda_map.Len(), len(expected))
+	}
+	if !maps.Equal(maps.Collect(sfda_map.All()), expected) {
+		log.Fatalf("Iteration does not match what was set.\n")
+	}
+}
+
+func Test_RCU(n uint64) {
+	rcu_map := sfda_map.New_SFDA_RCU_Map[uint64, uint64](n)
+
+	rcu_map.Update(func(b *sfda_map.T_RCU_Batch[uint64, uint64]) {
+		for i := uint64(0); i < n; i++ {
+			b.Set(i, i)
+		}
+	})
+
+	// Readers must only ever see a whole batch: every key holds the same round number...
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				round := uint64(0)
+				first := true
+				for k, v := range rcu_map.All() {
+					if first {
+						round = v - k
+						first = false
+					}
+					if v-k != round {
+						log.Fatalf("Saw a half published batch at key %d.\n", k)
+					}
+				}
+			}
+		}()
+	}
+
+	for round := uint64(1); round <= 16; round++ {
+		rcu_map.Update(func(b *sfda_map.T_RCU_Batch[uint64, uint64]) {
+			for i := uint64(0); i < n; i++ {
+				b.Set(i, i+round)
+			}
+		})
+	}
+	close(done)
+	wg.Wait()
+
+	if rcu_map.Len() != int(n) {
+		log.Fatalf("Wrong length. Got %d\n", rcu_map.Len())
+	}
+	for i := uint64(0); i < n; i++ {
+		if x, ok := rcu_map.Lookup(i); !ok || x != i+16 {
+			log.Fatalf("Wrong value for key %d. Got %d\n", i, x)
+		}
+	}
+	if !rcu_map.Delete(1) || rcu_map.Delete(1) {
+		log.Fatalf("Delete should find the key exactly once.\n")
+	}
+}
+
+// Only checks anything when built with `-tags sfda_debug`, and not with `-race` since it races on purpose.
+func Test_Misuse_Detection(n uint64) {
+	if !sfda_map.MISUSE_DETECTION_ENABLED {
+		return
+	}
+
+	m := sfda_map.New[uint64, uint64](n)
+	for i := uint64(0); i < n; i++ {
+		m.Set(i, i)
+	}
+
+	// Writing from inside a loop over the map overlaps the write with the read...
+	func() {
+		defer func() {
+			r := recover()
+			if r != "sfda_map: concurrent map read and map write" {
+				log.Fatalf("Expected a misuse panic. Got %v\n", r)
+			}
+		}()
+		for k := range m.Keys() {
+			m.Set(k+n, k)
+		}
+	}()
+
+	// The panic must not leave the map looking busy...
+	m.Set(n, n)
+	if v, ok := m.Lookup(n); !ok || v != n {
+		log.Fatalf("Wrong value for key %d. Got %d\n", n, v)
+	}
+	for range m.All() {
+		m.Lookup(0)
+	}
+
+	// Goroutines writing to the same map are caught sooner or later...
+	var wg sync.WaitGroup
+	var caught atomic.Bool
+	for g := uint64(0); g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					if r != "sfda_map: concurrent map writes" {
+						log.Fatalf("Expected a misuse panic. Got %v\n", r)
+					}
+					caught.Store(true)
+				}
+			}()
+			for i := uint64(0); i < 1<<20 && !caught.Load(); i++ {
+				m.Set(i%n+g*n, i)
+				m.Delete(i%n + g*n)
+			}
+		}()
+	}
+	wg.Wait()
+	if !caught.Load() {
+		log.Fatalf("Concurrent writes were not caught.\n")
+	}
+}
+
+func Test_Concurrent_Numeric(n uint32) {
+	counters := sfda_map.New_SFDA_Concurrent_Numeric_Map[uint32, int64](n, 16)
+
+	var wg sync.WaitGroup
+	num_goroutines := 8
+
+	// Every goroutine bumps every key, both with `Add` and with a `Compare_And_Swap` loop...
+	wg.Add(num_goroutines)
+	for g := 0; g < num_goroutines; g++ {
+		go func() {
+			defer wg.Done()
+			for key := uint32(0); key < n; key++ {
+				counters.Add(key, 1)
+				for {
+					x, _ := counters.Lookup(key)
+					if counters.Compare_And_Swap(key, x, x+10) {
+						break
+					}
+				}
+			}
+		}()
+	}
+
+	// Meanwhile, values only ever grow, and never past what every goroutine adds...
+	done := make(chan struct{})
+	var readers sync.WaitGroup
+	readers.Add(1)
+	go func() {
+		defer readers.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			for key, x := range counters.All() {
+				if x < 1 || x > int64(num_goroutines)*11 || counters.Get_Or_Default(key, 0) < x {
+					log.Fatalf("Wrong count for key %d. Got %d\n", key, x)
+				}
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(done)
+	readers.Wait()
+
+	expected := int64(num_goroutines) * 11
+	for key := uint32(0); key < n; key++ {
+		if x := counters.Add(key, -1); x != expected-1 {
+			log.Fatalf("Wrong count for key %d. Got %d, expected %d\n", key, x, expected-1)
+		}
+		if old, loaded := counters.Swap(key, 0); !loaded || old != expected-1 {
+			log.Fatalf("Wrong swap for key %d. Got %d, %t\n", key, old, loaded)
+		}
+	}
+
+	// A missing key counts as 0 for `Add`, and never matches for `Compare_And_Swap`...
+	if counters.Compare_And_Swap(n, 0, 1) {
+		log.Fatalf("Compare_And_Swap matched a missing key.\n")
+	}
+	if old, loaded := counters.Swap(n, 5); loaded || old != 0 {
+		log.Fatalf("Swap found a missing key.\n")
+	}
+	if x := counters.Add(n+1, -3); x != -3 {
+		log.Fatalf("Wrong count for a new key. Got %d\n", x)
+	}
+	if counters.Len() != int(n)+2 {
+		log.Fatalf("Wrong length. Got %d, expected %d\n", counters.Len(), n+2)
+	}
+}